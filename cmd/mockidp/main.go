@@ -0,0 +1,220 @@
+// mockidp is local OpenID Connect provider for manual testing of single sign-on.
+//
+// authorization endpoint approves every request without login page, subject is taken from
+// "login_hint" query param or "-sub" flag. Start with:
+//
+//	go run ./cmd/mockidp -a localhost:9000
+//
+// and run shortener with OIDC_ISSUER=http://localhost:9000 OIDC_CLIENT_ID=shortener.
+package main
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"flag"
+	"log"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/hollgett/shortener.git/internal/oidc"
+)
+
+const (
+	keyID    = "mock-key"
+	codeTTL  = time.Minute
+	tokenTTL = 10 * time.Minute
+)
+
+type authCode struct {
+	clientID      string
+	redirectURI   string
+	nonce         string
+	challenge     string
+	subject       string
+	expiresAt     time.Time
+	challengeType string
+}
+
+type mockIdP struct {
+	issuer       string
+	clientSecret string
+	subject      string
+	key          *rsa.PrivateKey
+
+	mu    sync.Mutex
+	codes map[string]authCode
+}
+
+func main() {
+	addr := flag.String("a", "localhost:9000", "address of mock provider")
+	issuer := flag.String("i", "", "issuer, default http://<address>")
+	clientSecret := flag.String("s", "", "required client secret, empty disables check")
+	subject := flag.String("sub", "mock-user", "default subject of issued id tokens")
+	flag.Parse()
+
+	if len(*issuer) == 0 {
+		*issuer = "http://" + *addr
+	}
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		log.Fatal(err)
+	}
+	idp := &mockIdP{
+		issuer:       *issuer,
+		clientSecret: *clientSecret,
+		subject:      *subject,
+		key:          key,
+		codes:        make(map[string]authCode),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", idp.discovery)
+	mux.HandleFunc("/authorize", idp.authorize)
+	mux.HandleFunc("/token", idp.token)
+	mux.HandleFunc("/jwks", idp.jwks)
+
+	log.Printf("mock identity provider %s on %s", *issuer, *addr)
+	log.Fatal(http.ListenAndServe(*addr, mux))
+}
+
+func (m *mockIdP) discovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{
+		"issuer":                                m.issuer,
+		"authorization_endpoint":                m.issuer + "/authorize",
+		"token_endpoint":                        m.issuer + "/token",
+		"jwks_uri":                              m.issuer + "/jwks",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"code_challenge_methods_supported":      []string{"S256"},
+	})
+}
+
+func (m *mockIdP) jwks(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, oidc.JSONWebKeySet{
+		Keys: []oidc.JSONWebKey{oidc.NewRSAJSONWebKey(keyID, &m.key.PublicKey)},
+	})
+}
+
+// approve request and redirect back with code
+func (m *mockIdP) authorize(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	redirectURI, err := url.Parse(q.Get("redirect_uri"))
+	if err != nil || len(q.Get("redirect_uri")) == 0 {
+		http.Error(w, "invalid redirect_uri", http.StatusBadRequest)
+		return
+	}
+	if q.Get("response_type") != "code" {
+		http.Error(w, "unsupported response_type", http.StatusBadRequest)
+		return
+	}
+
+	subject := q.Get("login_hint")
+	if len(subject) == 0 {
+		subject = m.subject
+	}
+	code := oidc.RandomString(16)
+	m.mu.Lock()
+	m.codes[code] = authCode{
+		clientID:      q.Get("client_id"),
+		redirectURI:   q.Get("redirect_uri"),
+		nonce:         q.Get("nonce"),
+		challenge:     q.Get("code_challenge"),
+		challengeType: q.Get("code_challenge_method"),
+		subject:       subject,
+		expiresAt:     time.Now().Add(codeTTL),
+	}
+	m.mu.Unlock()
+
+	back := redirectURI.Query()
+	back.Set("code", code)
+	back.Set("state", q.Get("state"))
+	redirectURI.RawQuery = back.Encode()
+	http.Redirect(w, r, redirectURI.String(), http.StatusFound)
+}
+
+// exchange code to id token, PKCE verifier is checked
+func (m *mockIdP) token(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		tokenError(w, "invalid_request", err.Error())
+		return
+	}
+
+	m.mu.Lock()
+	code, ok := m.codes[r.PostForm.Get("code")]
+	delete(m.codes, r.PostForm.Get("code"))
+	m.mu.Unlock()
+
+	switch {
+	case !ok || time.Now().After(code.expiresAt):
+		tokenError(w, "invalid_grant", "unknown or expired code")
+		return
+	case code.clientID != r.PostForm.Get("client_id") || code.redirectURI != r.PostForm.Get("redirect_uri"):
+		tokenError(w, "invalid_grant", "client_id or redirect_uri mismatch")
+		return
+	case len(m.clientSecret) != 0 && m.clientSecret != r.PostForm.Get("client_secret"):
+		tokenError(w, "invalid_client", "invalid client secret")
+		return
+	case len(code.challenge) != 0 && !verifyChallenge(code, r.PostForm.Get("code_verifier")):
+		tokenError(w, "invalid_grant", "pkce verification failed")
+		return
+	}
+
+	now := time.Now()
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, oidc.IDTokenClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    m.issuer,
+			Subject:   code.subject,
+			Audience:  jwt.ClaimStrings{code.clientID},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(tokenTTL)),
+		},
+		Nonce:         code.nonce,
+		Email:         code.subject + "@mock.local",
+		EmailVerified: true,
+		Name:          code.subject,
+	})
+	token.Header["kid"] = keyID
+	idToken, err := token.SignedString(m.key)
+	if err != nil {
+		tokenError(w, "server_error", err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"access_token": oidc.RandomString(16),
+		"token_type":   "Bearer",
+		"expires_in":   int(tokenTTL.Seconds()),
+		"id_token":     idToken,
+	})
+}
+
+func verifyChallenge(code authCode, verifier string) bool {
+	if code.challengeType != "S256" {
+		return code.challenge == verifier
+	}
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:]) == code.challenge
+}
+
+func tokenError(w http.ResponseWriter, code, description string) {
+	writeJSON(w, http.StatusBadRequest, map[string]string{
+		"error":             code,
+		"error_description": description,
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, data any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(data)
+}
@@ -1,8 +1,21 @@
 package main
 
-import "github.com/hollgett/shortener.git/internal/app"
+import (
+	"fmt"
+	"os"
+
+	"github.com/hollgett/shortener.git/internal/app"
+)
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == app.MigrateStoreCommand {
+		if err := app.MigrateStore(os.Args[2:]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	app := app.NewApp()
 	app.Run()
 }
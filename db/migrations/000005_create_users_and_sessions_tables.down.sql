@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS shortener_sessions;
+
+DROP TABLE IF EXISTS shortener_users;
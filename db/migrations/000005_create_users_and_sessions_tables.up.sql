@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS shortener_users (
+    id VARCHAR(8) PRIMARY KEY,
+    login VARCHAR(255) NOT NULL,
+    password_hash VARCHAR(255) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT login_unique UNIQUE(login)
+);
+
+CREATE TABLE IF NOT EXISTS shortener_sessions (
+    id VARCHAR(64) PRIMARY KEY,
+    user_id VARCHAR(8) NOT NULL REFERENCES shortener_users(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    revoked BOOLEAN NOT NULL DEFAULT false
+);
+
+CREATE INDEX IF NOT EXISTS shortener_sessions_user_id_idx ON shortener_sessions(user_id);
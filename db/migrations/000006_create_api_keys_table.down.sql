@@ -0,0 +1 @@
+DROP TABLE IF EXISTS shortener_api_keys;
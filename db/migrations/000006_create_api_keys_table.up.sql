@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS shortener_api_keys (
+    id VARCHAR(32) PRIMARY KEY,
+    user_id VARCHAR(8) NOT NULL,
+    name VARCHAR(255) NOT NULL DEFAULT '',
+    hash VARCHAR(64) NOT NULL,
+    scopes TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    last_used_at TIMESTAMPTZ,
+    expires_at TIMESTAMPTZ,
+    revoked BOOLEAN NOT NULL DEFAULT false
+);
+
+CREATE INDEX IF NOT EXISTS shortener_api_keys_user_id_idx ON shortener_api_keys(user_id);
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS shortener_user_identities;
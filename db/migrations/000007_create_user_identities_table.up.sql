@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS shortener_user_identities (
+    issuer VARCHAR(512) NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    user_id VARCHAR(8) NOT NULL REFERENCES shortener_users(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (issuer, subject)
+);
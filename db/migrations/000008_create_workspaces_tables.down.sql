@@ -0,0 +1,6 @@
+ALTER TABLE shortener_urls
+    DROP COLUMN workspace_id;
+
+DROP TABLE IF EXISTS shortener_workspace_members;
+
+DROP TABLE IF EXISTS shortener_workspaces;
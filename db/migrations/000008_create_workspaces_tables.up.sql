@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS shortener_workspaces (
+    id VARCHAR(32) PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS shortener_workspace_members (
+    workspace_id VARCHAR(32) NOT NULL REFERENCES shortener_workspaces(id) ON DELETE CASCADE,
+    user_id VARCHAR(8) NOT NULL,
+    role VARCHAR(16) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (workspace_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS shortener_workspace_members_user_id_idx ON shortener_workspace_members(user_id);
+
+ALTER TABLE shortener_urls
+    ADD COLUMN workspace_id VARCHAR(32) REFERENCES shortener_workspaces(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS shortener_urls_workspace_id_idx ON shortener_urls(workspace_id);
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS shortener_audit_log;
+
+ALTER TABLE shortener_urls
+    DROP COLUMN is_disabled;
@@ -0,0 +1,13 @@
+ALTER TABLE shortener_urls
+    ADD COLUMN is_disabled BOOLEAN NOT NULL DEFAULT false;
+
+CREATE TABLE IF NOT EXISTS shortener_audit_log (
+    id VARCHAR(32) PRIMARY KEY,
+    actor_id VARCHAR(8) NOT NULL,
+    action VARCHAR(64) NOT NULL,
+    short VARCHAR(1024) NOT NULL DEFAULT '',
+    details TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS shortener_audit_log_created_at_idx ON shortener_audit_log(created_at);
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS shortener_audit_log_short_idx;
+
+ALTER TABLE shortener_audit_log
+    DROP COLUMN request_id,
+    DROP COLUMN ip,
+    DROP COLUMN before_value,
+    DROP COLUMN after_value;
@@ -0,0 +1,7 @@
+ALTER TABLE shortener_audit_log
+    ADD COLUMN request_id VARCHAR(64) NOT NULL DEFAULT '',
+    ADD COLUMN ip VARCHAR(64) NOT NULL DEFAULT '',
+    ADD COLUMN before_value TEXT NOT NULL DEFAULT '',
+    ADD COLUMN after_value TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS shortener_audit_log_short_idx ON shortener_audit_log(short);
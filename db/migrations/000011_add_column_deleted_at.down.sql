@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS shortener_urls_deleted_at_idx;
+
+ALTER TABLE shortener_urls
+    DROP COLUMN deleted_at;
@@ -0,0 +1,6 @@
+ALTER TABLE shortener_urls
+    ADD COLUMN deleted_at TIMESTAMPTZ;
+
+UPDATE shortener_urls SET deleted_at = now() WHERE is_deleted = true;
+
+CREATE INDEX IF NOT EXISTS shortener_urls_deleted_at_idx ON shortener_urls(deleted_at) WHERE is_deleted = true;
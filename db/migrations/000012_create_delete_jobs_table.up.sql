@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS shortener_delete_jobs (
+    id VARCHAR(32) PRIMARY KEY,
+    user_id VARCHAR(8) NOT NULL,
+    short VARCHAR(1024) NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    last_error TEXT NOT NULL DEFAULT '',
+    is_dead BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS shortener_delete_jobs_next_attempt_at_idx ON shortener_delete_jobs(next_attempt_at) WHERE is_dead = false;
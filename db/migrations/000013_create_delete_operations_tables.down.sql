@@ -0,0 +1,5 @@
+ALTER TABLE shortener_delete_jobs
+    DROP COLUMN operation_id;
+
+DROP TABLE IF EXISTS shortener_delete_operation_urls;
+DROP TABLE IF EXISTS shortener_delete_operations;
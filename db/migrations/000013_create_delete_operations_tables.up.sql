@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS shortener_delete_operations (
+    id VARCHAR(32) PRIMARY KEY,
+    user_id VARCHAR(8) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS shortener_delete_operation_urls (
+    operation_id VARCHAR(32) NOT NULL REFERENCES shortener_delete_operations(id) ON DELETE CASCADE,
+    short VARCHAR(1024) NOT NULL,
+    status VARCHAR(16) NOT NULL,
+    error TEXT NOT NULL DEFAULT '',
+    PRIMARY KEY (operation_id, short)
+);
+
+ALTER TABLE shortener_delete_jobs
+    ADD COLUMN operation_id VARCHAR(32) NOT NULL DEFAULT '';
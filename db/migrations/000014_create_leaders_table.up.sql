@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS shortener_leaders (
+    name VARCHAR(64) PRIMARY KEY,
+    instance_id VARCHAR(128) NOT NULL,
+    acquired_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    renewed_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
@@ -0,0 +1,8 @@
+ALTER TABLE shortener_urls
+    DROP CONSTRAINT IF EXISTS dedup_key_unique;
+
+ALTER TABLE shortener_urls
+    DROP COLUMN IF EXISTS dedup_key;
+
+ALTER TABLE shortener_urls
+    ADD CONSTRAINT original_unique UNIQUE(original);
@@ -0,0 +1,10 @@
+ALTER TABLE shortener_urls
+    ADD COLUMN IF NOT EXISTS dedup_key TEXT;
+
+UPDATE shortener_urls SET dedup_key = original;
+
+ALTER TABLE shortener_urls
+    ADD CONSTRAINT dedup_key_unique UNIQUE(dedup_key);
+
+ALTER TABLE shortener_urls
+    DROP CONSTRAINT IF EXISTS original_unique;
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS shortener_idempotency_keys (
+    user_id VARCHAR(8) NOT NULL,
+    key VARCHAR(255) NOT NULL,
+    request_hash VARCHAR(64) NOT NULL,
+    status INTEGER NOT NULL DEFAULT 0,
+    content_type VARCHAR(255) NOT NULL DEFAULT '',
+    body BYTEA NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (user_id, key)
+);
+
+CREATE INDEX IF NOT EXISTS shortener_idempotency_keys_expires_at_idx ON shortener_idempotency_keys(expires_at);
@@ -0,0 +1,4 @@
+ALTER TABLE shortener_urls
+    ADD COLUMN IF NOT EXISTS redirect_status SMALLINT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS cache_control VARCHAR(255) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS tracked BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,3 @@
+ALTER TABLE shortener_urls
+    DROP COLUMN IF EXISTS title,
+    DROP COLUMN IF EXISTS created_at;
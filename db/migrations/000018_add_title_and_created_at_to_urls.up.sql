@@ -0,0 +1,6 @@
+ALTER TABLE shortener_urls
+    ADD COLUMN IF NOT EXISTS title VARCHAR(255) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS created_at TIMESTAMPTZ;
+
+ALTER TABLE shortener_urls
+    ALTER COLUMN created_at SET DEFAULT now();
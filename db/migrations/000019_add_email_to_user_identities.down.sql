@@ -0,0 +1,2 @@
+ALTER TABLE shortener_user_identities
+    DROP COLUMN IF EXISTS email;
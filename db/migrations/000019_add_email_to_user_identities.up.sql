@@ -0,0 +1,12 @@
+ALTER TABLE shortener_user_identities
+    ADD COLUMN IF NOT EXISTS email VARCHAR(255) NOT NULL DEFAULT '';
+
+UPDATE shortener_user_identities i
+SET email = u.login
+FROM shortener_users u
+WHERE i.user_id = u.id AND u.password_hash = '' AND u.login NOT LIKE 'oidc:%';
+
+UPDATE shortener_users u
+SET login = 'oidc:' || encode(sha256(convert_to(i.issuer || ' ' || i.subject, 'UTF8')), 'hex')
+FROM (SELECT DISTINCT ON (user_id) user_id, issuer, subject FROM shortener_user_identities ORDER BY user_id, created_at) i
+WHERE i.user_id = u.id AND u.password_hash = '' AND u.login NOT LIKE 'oidc:%';
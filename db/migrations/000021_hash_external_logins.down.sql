@@ -0,0 +1,4 @@
+UPDATE shortener_users u
+SET login = 'oidc:' || i.subject
+FROM (SELECT DISTINCT ON (user_id) user_id, subject FROM shortener_user_identities ORDER BY user_id, created_at) i
+WHERE i.user_id = u.id AND u.password_hash = '' AND u.login LIKE 'oidc:%' AND length(i.subject) <= 250;
@@ -3,16 +3,20 @@ package app
 import (
 	"context"
 	"errors"
+	"fmt"
 	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/hollgett/shortener.git/internal/config"
 	"github.com/hollgett/shortener.git/internal/handlers"
 	"github.com/hollgett/shortener.git/internal/logger"
+	"github.com/hollgett/shortener.git/internal/models"
+	"github.com/hollgett/shortener.git/internal/oidc"
 	"github.com/hollgett/shortener.git/internal/service"
 	"github.com/hollgett/shortener.git/internal/store"
 	"github.com/hollgett/shortener.git/internal/worker"
@@ -25,14 +29,17 @@ const (
 	shutDownHardPeriod = 5 * time.Second
 )
 
+// lock of leader which runs scheduled jobs
+const leaderLockName = "scheduled-jobs"
+
 type App struct {
-	logger       *logger.Logger
-	cfg          *config.ShortenerConfig
-	store        store.Store
-	service      *service.Service
-	workerDelete *worker.DeleteWorker
-	handlers     *handlers.Handlers
-	middleware   *handlers.Middleware
+	logger     *logger.Logger
+	cfg        *config.ShortenerConfig
+	store      store.Store
+	service    *service.Service
+	workers    *worker.Runner
+	handlers   *handlers.Handlers
+	middleware *handlers.Middleware
 }
 
 func NewApp() *App {
@@ -54,6 +61,10 @@ func (a *App) Run() {
 	//get layers
 	a.setLayers()
 
+	// background jobs are stopped after server, so queued deletes are handled
+	workersCtx, stopWorkers := context.WithCancel(context.Background())
+	a.workers.Start(workersCtx)
+
 	// get server with base context and routers
 	ongoingCtx, stopOngoingCtx := context.WithCancel(context.Background())
 	server := a.buildServer(ongoingCtx)
@@ -78,7 +89,8 @@ func (a *App) Run() {
 		time.Sleep(shutDownHardPeriod)
 	}
 
-	a.workerDelete.ShutDown()
+	stopWorkers()
+	a.workers.Wait()
 
 	err = a.store.Close()
 	if err != nil {
@@ -100,23 +112,173 @@ func (a *App) setLogger() {
 // set layers store, service, handler
 func (a *App) setLayers() {
 	//get service
-	var err error
-	if a.store, err = store.NewStore(a.logger, a.cfg.FilePath, a.cfg.DatabaseDSN); err != nil {
+	dedup, err := store.ParseDedupScope(a.cfg.DedupScope)
+	if err != nil {
 		panic(err)
 	}
+	if a.store, err = store.NewStore(a.logger, a.cfg.FilePath, a.cfg.DatabaseDSN, store.PoolConfig{
+		MaxConns:          int32(a.cfg.DBMaxConns),
+		MinConns:          int32(a.cfg.DBMinConns),
+		MaxConnLifetime:   a.cfg.DBMaxConnLifetime,
+		MaxConnIdleTime:   a.cfg.DBMaxConnIdleTime,
+		HealthCheckPeriod: a.cfg.DBHealthCheckPeriod,
+		StatementCache:    a.cfg.DBStatementCache,
+		QueryTimeout:      a.cfg.DBQueryTimeout,
+	}, dedup); err != nil {
+		panic(err)
+	}
+	a.workers = worker.NewRunner()
+
+	//get redirect cache
+	if a.cfg.CacheSize > 0 {
+		cachedStore := store.NewCachedStore(a.logger, a.store, store.CacheConfig{
+			Size:        a.cfg.CacheSize,
+			TTL:         a.cfg.CacheTTL,
+			NegativeTTL: a.cfg.CacheNegativeTTL,
+		})
+		a.workers.Add(cachedStore)
+		a.store = cachedStore
+	}
 
-	//get worker
-	a.workerDelete = worker.NewDeleteWorker(a.logger, a.store)
-	go a.workerDelete.Run()
+	//get workers
+	hooks := worker.LogHooks(a.logger)
+	elector := worker.NewElector(a.logger, a.store.NewLeaderLock(leaderLockName, a.instanceID()), leaderLockName, a.instanceID())
+	workerDelete := worker.NewDeleteWorker(a.logger, a.store, elector, hooks)
+	a.workers.Add(elector)
+	a.workers.Add(workerDelete.Jobs()...)
 
 	//get service
-	a.service = service.NewService(a.logger, a.store, a.workerDelete.DeleteCh)
+	a.service = service.NewService(a.logger, a.store, workerDelete, elector, a.adminLogins(), a.cfg.IdempotencyTTL)
+
+	//get purge of deleted links, disabled without retention
+	if a.cfg.DeletedRetention > 0 {
+		schedule, err := worker.ParseCron(a.cfg.DeletedPurgeSchedule)
+		if err != nil {
+			panic(err)
+		}
+		a.workers.Add(worker.NewPurgeJob(a.logger, a.service, a.cfg.DeletedRetention, schedule, elector, hooks))
+	}
+	if a.cfg.IdempotencyTTL > 0 {
+		a.workers.Add(worker.NewIdempotencyPurgeJob(a.logger, a.service, elector, hooks))
+	}
+
+	//get auth tokens
+	tokens := handlers.NewTokenIssuer(a.buildKeyring(), a.buildAuthOptions())
 
 	//get handlers
-	a.handlers = handlers.NewHandlers(a.logger, a.service, a.cfg.BaseURL)
+	a.handlers = handlers.NewHandlers(a.logger, a.service, tokens, a.buildOIDCProvider(), a.cfg.BaseURL, handlers.BatchConfig{
+		MaxSize:     a.cfg.BatchMaxSize,
+		StreamChunk: a.cfg.BatchStreamChunk,
+	}, a.buildRedirectConfig())
 
 	//get middleware
-	a.middleware = handlers.NewMiddleware(a.logger, a.cfg.SecretKey)
+	a.middleware = handlers.NewMiddleware(a.logger, tokens, a.service, a.service)
+}
+
+// build auth cookie attributes and token lifetime
+func (a *App) buildAuthOptions() handlers.AuthOptions {
+	sameSite, err := handlers.ParseSameSite(a.cfg.CookieSameSite)
+	if err != nil {
+		panic(err)
+	}
+	return handlers.AuthOptions{
+		CookieDomain: a.cfg.CookieDomain,
+		// browsers reject SameSite=None cookies without Secure
+		CookieSecure:   a.cfg.CookieSecure || sameSite == http.SameSiteNoneMode,
+		CookieSameSite: sameSite,
+		TokenTTL:       a.cfg.TokenTTL,
+		RefreshBefore:  a.cfg.TokenRefreshBefore,
+	}
+}
+
+// build global redirect policy, applied to links without own policy
+func (a *App) buildRedirectConfig() handlers.RedirectConfig {
+	err := service.ValidateRedirectPolicy(models.RedirectPolicy{
+		RedirectStatus: a.cfg.RedirectStatus,
+		CacheControl:   a.cfg.RedirectCacheControl,
+	})
+	if err != nil {
+		panic(err)
+	}
+	if a.cfg.RedirectStatus == 0 {
+		panic(fmt.Errorf("%w: default status is required", service.ErrInvalidRedirect))
+	}
+	return handlers.RedirectConfig{
+		Status:       a.cfg.RedirectStatus,
+		CacheControl: a.cfg.RedirectCacheControl,
+
+		PreviewExternal: a.cfg.PreviewExternal,
+	}
+}
+
+// id of instance from config, default is host name with process id
+func (a *App) instanceID() string {
+	if len(a.cfg.InstanceID) != 0 {
+		return a.cfg.InstanceID
+	}
+	host, err := os.Hostname()
+	if err != nil {
+		host = "localhost"
+	}
+	return fmt.Sprintf("%s-%d", host, os.Getpid())
+}
+
+// split admin logins from config, empty list disables admin api
+func (a *App) adminLogins() []string {
+	logins := make([]string, 0)
+	for _, login := range strings.Split(a.cfg.AdminLogins, ",") {
+		if login = strings.TrimSpace(login); len(login) != 0 {
+			logins = append(logins, login)
+		}
+	}
+	return logins
+}
+
+// parse trusted subnet from config, return nil if subnet is not set
+func (a *App) buildTrustedSubnet() *net.IPNet {
+	if len(strings.TrimSpace(a.cfg.TrustedSubnet)) == 0 {
+		return nil
+	}
+	_, subnet, err := net.ParseCIDR(strings.TrimSpace(a.cfg.TrustedSubnet))
+	if err != nil {
+		panic(fmt.Errorf("invalid trusted subnet: %w", err))
+	}
+	return subnet
+}
+
+// build OpenID Connect provider, return nil if single sign-on is not configured
+func (a *App) buildOIDCProvider() *oidc.Provider {
+	if len(a.cfg.OIDCIssuer) == 0 {
+		return nil
+	}
+	redirectURL := a.cfg.OIDCRedirectURL
+	if len(redirectURL) == 0 {
+		redirectURL = strings.TrimRight(a.cfg.BaseURL, "/") + handlers.OIDCCallbackPath
+	}
+	a.logger.Info("single sign-on enabled", zap.String("issuer", a.cfg.OIDCIssuer))
+	return oidc.NewProvider(oidc.Config{
+		Issuer:       a.cfg.OIDCIssuer,
+		ClientID:     a.cfg.OIDCClientID,
+		ClientSecret: a.cfg.OIDCClientSecret,
+		RedirectURL:  redirectURL,
+		Scopes:       strings.Fields(a.cfg.OIDCScopes),
+	})
+}
+
+// build keyring for signing auth tokens from current and previous secret keys
+func (a *App) buildKeyring() *handlers.Keyring {
+	previous, err := handlers.ParseSigningKeys(a.cfg.PreviousSecretKeys)
+	if err != nil {
+		panic(err)
+	}
+	keyring, err := handlers.NewKeyring(handlers.SigningKey{
+		ID:     a.cfg.SecretKeyID,
+		Secret: []byte(a.cfg.SecretKey),
+	}, previous...)
+	if err != nil {
+		panic(err)
+	}
+	return keyring
 }
 
 // creates server with config and routes
@@ -134,11 +296,30 @@ func (a *App) buildServer(ongoingCtx context.Context) *http.Server {
 func (a *App) setRouter() http.Handler {
 	mux := http.NewServeMux()
 
-	mux.HandleFunc("/", a.handlers.CreateOrRedirectText)
+	mux.Handle("/", a.middleware.Idempotent(http.HandlerFunc(a.handlers.CreateOrRedirectText)))
 	mux.HandleFunc("/ping", a.handlers.PingDatabase)
-	mux.HandleFunc("/api/shorten", a.handlers.CreateAPIShortURL)
-	mux.HandleFunc("/api/shorten/batch", a.handlers.CreateAPIShortURLs)
+	mux.Handle("/api/shorten", a.middleware.Idempotent(http.HandlerFunc(a.handlers.CreateAPIShortURL)))
+	mux.Handle("/api/shorten/batch", a.middleware.Idempotent(http.HandlerFunc(a.handlers.CreateAPIShortURLs)))
 	mux.HandleFunc("/api/user/urls", a.handlers.ControllerUserURLs)
+	mux.HandleFunc("/api/user/urls/", a.handlers.UpdateAPIUserURL)
+	mux.HandleFunc("/api/user/urls/trash", a.handlers.GetAPIUserTrash)
+	mux.HandleFunc("/api/user/urls/restore", a.handlers.RestoreAPIUserURLs)
+	mux.HandleFunc("/api/user/urls/export", a.handlers.ExportAPIUserURLs)
+	mux.Handle("/api/user/urls/import", a.middleware.Idempotent(http.HandlerFunc(a.handlers.ImportAPIUserURLs)))
+	mux.HandleFunc("/api/user/operations/", a.handlers.GetAPIUserOperation)
+	mux.HandleFunc("/api/user/register", a.handlers.RegisterUser)
+	mux.HandleFunc("/api/user/login", a.handlers.LoginUser)
+	mux.HandleFunc("/api/user/logout", a.handlers.LogoutUser)
+	mux.HandleFunc("/api/user/keys", a.handlers.ControllerAPIKeys)
+	mux.HandleFunc("/api/user/keys/", a.handlers.ControllerAPIKeys)
+	mux.HandleFunc("/api/auth/oidc/login", a.handlers.LoginOIDC)
+	mux.HandleFunc(handlers.OIDCCallbackPath, a.handlers.CallbackOIDC)
+	mux.HandleFunc("/api/workspaces", a.handlers.ControllerWorkspaces)
+	mux.HandleFunc("/api/workspaces/", a.handlers.ControllerWorkspaces)
+	mux.HandleFunc("/api/admin/", a.handlers.ControllerAdmin)
+	trustedSubnet := a.middleware.TrustedSubnet(a.buildTrustedSubnet())
+	mux.Handle("/api/internal/stats", trustedSubnet(http.HandlerFunc(a.handlers.GetInternalStats)))
+	mux.Handle("/api/internal/leader", trustedSubnet(http.HandlerFunc(a.handlers.GetInternalLeader)))
 	mux.HandleFunc("/api/test", func(w http.ResponseWriter, r *http.Request) {
 		userID := r.Context().Value(handlers.UserKeyCtx)
 		val, ok := userID.(string)
@@ -155,5 +336,6 @@ func (a *App) setRouter() http.Handler {
 		a.middleware.UnCompress,
 		a.middleware.Compress,
 		a.middleware.ResponseLogged,
+		a.middleware.RequestID,
 	)
 }
@@ -0,0 +1,181 @@
+package app
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/hollgett/shortener.git/internal/logger"
+	"github.com/hollgett/shortener.git/internal/models"
+	"github.com/hollgett/shortener.git/internal/store"
+	"go.uber.org/zap"
+)
+
+// MigrateStoreCommand name of subcommand which copies links between stores
+const MigrateStoreCommand = "migrate-store"
+
+// MigrateStore copy all links from one store to another by pages ordered by short.
+//
+// stores are set as "file:<path>", "postgres:<dsn>", links existing in target are skipped so migration can be repeated.
+// Source is only read. In dry run target is only read too, links are imported to its copy in memory.
+func MigrateStore(args []string) error {
+	flags := flag.NewFlagSet(MigrateStoreCommand, flag.ContinueOnError)
+	from := flags.String("from", "", "set source store, file:<path> or postgres:<dsn>")
+	to := flags.String("to", "", "set target store, file:<path> or postgres:<dsn>")
+	batch := flags.Int("batch", 500, "set count of links copied at once")
+	dryRun := flags.Bool("dry-run", false, "count links which would be copied without writing target")
+	dedupScope := flags.String("dedup-scope", string(store.DedupGlobal), "set scope of deduplication of original URLs in target: global, user or none")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *from == "" || *to == "" {
+		return errors.New("both -from and -to are required")
+	}
+	if *batch <= 0 {
+		return errors.New("batch must be positive")
+	}
+	dedup, err := store.ParseDedupScope(*dedupScope)
+	if err != nil {
+		return err
+	}
+
+	logger, err := logger.NewLogger()
+	if err != nil {
+		return fmt.Errorf("failed build logger: %w", err)
+	}
+	defer logger.Close()
+
+	source, err := openReadStore(logger, *from, store.DedupGlobal)
+	if err != nil {
+		return fmt.Errorf("failed open source store: %w", err)
+	}
+	defer source.Close()
+	var target importStore
+	if *dryRun {
+		target, err = previewStore(logger, *to, dedup, *batch)
+	} else {
+		target, err = openStore(logger, *to, dedup)
+	}
+	if err != nil {
+		return fmt.Errorf("failed open target store: %w", err)
+	}
+	defer target.Close()
+
+	stats, err := source.GetStats()
+	if err != nil {
+		return fmt.Errorf("failed get source stats: %w", err)
+	}
+	logger.Info("migrate store start", zap.Int("total", stats.URLs), zap.Bool("dry_run", *dryRun))
+
+	var read, copied int
+	after := ""
+	for {
+		URLs, err := source.ScanURLs("", after, *batch)
+		if err != nil {
+			return fmt.Errorf("failed read links after %q: %w", after, err)
+		}
+		if len(URLs) == 0 {
+			break
+		}
+		after = URLs[len(URLs)-1].ShortURL
+		read += len(URLs)
+
+		imported, err := target.ImportURLs(URLs)
+		if err != nil {
+			return fmt.Errorf("failed write links after %q: %w", after, err)
+		}
+		copied += imported
+		logger.Info("migrate store progress", zap.Int("read", read), zap.Int("total", stats.URLs),
+			zap.Int("copied", copied), zap.Int("skipped", read-copied))
+	}
+
+	logger.Info("migrate store done", zap.Int("read", read), zap.Int("copied", copied),
+		zap.Int("skipped", read-copied), zap.Bool("dry_run", *dryRun))
+	return nil
+}
+
+type readStore interface {
+	GetStats() (models.Stats, error)
+	ScanURLs(userID, afterShort string, limit int) ([]models.ShortenerURL, error)
+	Close() error
+}
+
+type importStore interface {
+	ImportURLs(URLs []models.ShortenerURL) (int, error)
+	Close() error
+}
+
+// split "kind:location" spec of store
+func parseStoreSpec(spec string) (kind, location string, err error) {
+	kind, location, ok := strings.Cut(spec, ":")
+	if !ok || location == "" {
+		return "", "", fmt.Errorf("invalid store %q, expected file:<path> or postgres:<dsn>", spec)
+	}
+	if kind != "file" && kind != "postgres" {
+		return "", "", fmt.Errorf("unknown store kind %q", kind)
+	}
+	return kind, location, nil
+}
+
+// open store for writing, database is migrated and files are created
+func openStore(logger *logger.Logger, spec string, dedup store.DedupScope) (store.Store, error) {
+	kind, location, err := parseStoreSpec(spec)
+	if err != nil {
+		return nil, err
+	}
+	if kind == "file" {
+		return store.NewFileStore(location, dedup)
+	}
+	return store.NewPostgreSQLStore(logger, location, store.PoolConfig{}, dedup)
+}
+
+// open store for reading without changes, database is not migrated and file is read to memory
+func openReadStore(logger *logger.Logger, spec string, dedup store.DedupScope) (readStore, error) {
+	kind, location, err := parseStoreSpec(spec)
+	if err != nil {
+		return nil, err
+	}
+	if kind == "file" {
+		return store.ReadFileURLs(location, dedup)
+	}
+	return store.OpenPostgreSQLStore(logger, location, store.PoolConfig{}, dedup)
+}
+
+// copy of target links in memory for dry run, import to it counts links same as import to target.
+// Database without tables is empty target.
+func previewStore(logger *logger.Logger, spec string, dedup store.DedupScope, batch int) (*store.InMemoryStore, error) {
+	kind, location, err := parseStoreSpec(spec)
+	if err != nil {
+		return nil, err
+	}
+	if kind == "file" {
+		return store.ReadFileURLs(location, dedup)
+	}
+
+	target, err := store.OpenPostgreSQLStore(logger, location, store.PoolConfig{}, dedup)
+	if err != nil {
+		return nil, err
+	}
+	defer target.Close()
+	preview := store.NewInMemoryStore(dedup)
+	ok, err := target.HasURLsTable()
+	if err != nil {
+		return nil, err
+	} else if !ok {
+		return preview, nil
+	}
+	for after := ""; ; {
+		URLs, err := target.ScanURLs("", after, batch)
+		if err != nil {
+			return nil, fmt.Errorf("failed read target links after %q: %w", after, err)
+		}
+		if len(URLs) == 0 {
+			return preview, nil
+		}
+		after = URLs[len(URLs)-1].ShortURL
+		if _, err := preview.ImportURLs(URLs); err != nil {
+			return nil, err
+		}
+	}
+}
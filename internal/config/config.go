@@ -2,8 +2,11 @@ package config
 
 import (
 	"flag"
+	"fmt"
 	"os"
+	"strconv"
 	"sync"
+	"time"
 
 	"github.com/hollgett/shortener.git/pkg/autoloaderenv"
 )
@@ -16,6 +19,63 @@ type ShortenerConfig struct {
 	FilePath    string `env:"FILE_STORAGE_PATH"`
 	DatabaseDSN string `env:"DATABASE_DSN"`
 	SecretKey   string `env:"SECRET_KEY"`
+	// id of current secret key, written to "kid" header of token
+	SecretKeyID string `env:"SECRET_KEY_ID"`
+	// previous keys accepted for verification, format "kid1:secret1,kid2:secret2"
+	PreviousSecretKeys string `env:"PREVIOUS_SECRET_KEYS"`
+	CookieDomain       string `env:"COOKIE_DOMAIN"`
+	CookieSecure       bool   `env:"COOKIE_SECURE"`
+	// lax, strict or none
+	CookieSameSite string `env:"COOKIE_SAME_SITE"`
+	// lifetime of auth token and cookie
+	TokenTTL time.Duration `env:"TOKEN_TTL"`
+	// auth token is re-issued when it expires earlier than this period
+	TokenRefreshBefore time.Duration `env:"TOKEN_REFRESH_BEFORE"`
+	// single sign-on is enabled if issuer is set
+	OIDCIssuer       string `env:"OIDC_ISSUER"`
+	OIDCClientID     string `env:"OIDC_CLIENT_ID"`
+	OIDCClientSecret string `env:"OIDC_CLIENT_SECRET"`
+	// default is BASE_URL with callback path
+	OIDCRedirectURL string `env:"OIDC_REDIRECT_URL"`
+	// space separated
+	OIDCScopes string `env:"OIDC_SCOPES"`
+	// comma separated logins of registered users allowed to use admin api
+	AdminLogins string `env:"ADMIN_LOGINS"`
+	// CIDR of clients allowed to get internal stats, empty forbid all
+	TrustedSubnet string `env:"TRUSTED_SUBNET"`
+	// deleted links are purged after this period, zero keeps them forever
+	DeletedRetention time.Duration `env:"DELETED_RETENTION"`
+	// cron schedule of purge of deleted links
+	DeletedPurgeSchedule string `env:"DELETED_PURGE_SCHEDULE"`
+	// id of replica in leader election, default is host name with process id
+	InstanceID string `env:"INSTANCE_ID"`
+	// max count of cached redirects, zero disables cache
+	CacheSize        int           `env:"CACHE_SIZE"`
+	CacheTTL         time.Duration `env:"CACHE_TTL"`
+	CacheNegativeTTL time.Duration `env:"CACHE_NEGATIVE_TTL"`
+	// tuning of PostgreSQL pool, zero values keep driver defaults
+	DBMaxConns          int           `env:"DB_MAX_CONNS"`
+	DBMinConns          int           `env:"DB_MIN_CONNS"`
+	DBMaxConnLifetime   time.Duration `env:"DB_MAX_CONN_LIFETIME"`
+	DBMaxConnIdleTime   time.Duration `env:"DB_MAX_CONN_IDLE_TIME"`
+	DBHealthCheckPeriod time.Duration `env:"DB_HEALTH_CHECK_PERIOD"`
+	// capacity of prepared statements cache per connection, zero disables cache
+	DBStatementCache int           `env:"DB_STATEMENT_CACHE"`
+	DBQueryTimeout   time.Duration `env:"DB_QUERY_TIMEOUT"`
+	// scope of deduplication of original URLs: global, user or none
+	DedupScope string `env:"DEDUP_SCOPE"`
+	// max count of links in json batch, zero is unlimited
+	BatchMaxSize int `env:"BATCH_MAX_SIZE"`
+	// count of links shortened at once in ndjson batch stream
+	BatchStreamChunk int `env:"BATCH_STREAM_CHUNK"`
+	// window in which create responses are replayed by Idempotency-Key header, zero disables
+	IdempotencyTTL time.Duration `env:"IDEMPOTENCY_TTL"`
+	// status of redirect for links without own status: 301, 302, 307 or 308
+	RedirectStatus int `env:"REDIRECT_STATUS"`
+	// Cache-Control of redirect for links without own value, empty sends no header
+	RedirectCacheControl string `env:"REDIRECT_CACHE_CONTROL"`
+	// links to other domains than base url open preview page instead of redirect
+	PreviewExternal bool `env:"PREVIEW_EXTERNAL"`
 }
 
 // NewConfig return struct config with filled args.
@@ -34,11 +94,38 @@ func (s *ShortenerConfig) fillConfig() {
 		flag.StringVar(&s.FilePath, "f", "tmp/short-url-db.json", "set filestorage mode")
 		flag.StringVar(&s.DatabaseDSN, "d", "", "set database PostgreSQL mode")
 		flag.BoolVar(&s.debug, "t", false, "setup debug mode")
+		flag.StringVar(&s.CookieSameSite, "cookie-same-site", "lax", "set SameSite of auth cookie: lax, strict, none")
+		flag.DurationVar(&s.TokenTTL, "token-ttl", 31*24*time.Hour, "set lifetime of auth token")
+		flag.DurationVar(&s.TokenRefreshBefore, "token-refresh", 7*24*time.Hour, "set period before expiry when auth token is re-issued")
+		flag.StringVar(&s.AdminLogins, "admins", "", "set comma separated logins of admins")
+		flag.StringVar(&s.TrustedSubnet, "trusted-subnet", "", "set CIDR of clients allowed to get internal stats")
+		flag.StringVar(&s.DeletedPurgeSchedule, "deleted-purge-schedule", "0 * * * *", "set cron schedule of purge of deleted links")
+		flag.IntVar(&s.CacheSize, "cache-size", 10000, "set max count of cached redirects, 0 disables cache")
+		flag.DurationVar(&s.CacheTTL, "cache-ttl", 5*time.Minute, "set lifetime of cached redirect")
+		flag.DurationVar(&s.CacheNegativeTTL, "cache-negative-ttl", 30*time.Second, "set lifetime of cached missing link, 0 disables")
+		flag.IntVar(&s.DBMaxConns, "db-max-conns", 0, "set max count of connections to database")
+		flag.IntVar(&s.DBMinConns, "db-min-conns", 0, "set min count of idle connections to database")
+		flag.DurationVar(&s.DBMaxConnLifetime, "db-max-conn-lifetime", 0, "set max lifetime of connection to database")
+		flag.DurationVar(&s.DBMaxConnIdleTime, "db-max-conn-idle-time", 0, "set max idle time of connection to database")
+		flag.DurationVar(&s.DBHealthCheckPeriod, "db-health-check-period", 0, "set period of health check of idle connections")
+		flag.IntVar(&s.DBStatementCache, "db-statement-cache", 512, "set capacity of prepared statements cache per connection, 0 disables cache")
+		flag.DurationVar(&s.DBQueryTimeout, "db-query-timeout", 5*time.Second, "set timeout of database query, 0 disables")
+		flag.IntVar(&s.BatchMaxSize, "batch-max-size", 10000, "set max count of links in json batch, 0 is unlimited")
+		flag.IntVar(&s.BatchStreamChunk, "batch-stream-chunk", 1000, "set count of links shortened at once in ndjson batch stream")
+		flag.DurationVar(&s.IdempotencyTTL, "idempotency-ttl", 24*time.Hour, "set window in which create responses are replayed by idempotency key, 0 disables")
+		flag.IntVar(&s.RedirectStatus, "redirect-status", 307, "set default status of redirect: 301, 302, 307 or 308")
+		flag.StringVar(&s.RedirectCacheControl, "redirect-cache-control", "", "set default Cache-Control of redirect, empty sends no header")
+		flag.BoolVar(&s.PreviewExternal, "preview-external", false, "set preview page instead of redirect for links to other domains")
+		flag.StringVar(&s.DedupScope, "dedup-scope", "global", "set scope of deduplication of original URLs: global, user or none")
+		flag.StringVar(&s.InstanceID, "instance-id", "", "set id of instance in leader election")
+		flag.DurationVar(&s.DeletedRetention, "deleted-retention", 30*24*time.Hour, "set period after which deleted links are purged, 0 disables purge")
 
 		flag.Parse()
 
 		if s.debug {
-			autoloaderenv.LoadEnv()
+			if err := autoloaderenv.LoadEnv(); err != nil {
+				panic(err)
+			}
 		}
 
 		addr, ok := os.LookupEnv("SERVER_ADDRESS")
@@ -63,6 +150,170 @@ func (s *ShortenerConfig) fillConfig() {
 		} else {
 			s.SecretKey = "secret_key"
 		}
+		secretKeyID, ok := os.LookupEnv("SECRET_KEY_ID")
+		if ok {
+			s.SecretKeyID = secretKeyID
+		} else {
+			s.SecretKeyID = "default"
+		}
+		previousSecretKeys, ok := os.LookupEnv("PREVIOUS_SECRET_KEYS")
+		if ok {
+			s.PreviousSecretKeys = previousSecretKeys
+		}
+		cookieDomain, ok := os.LookupEnv("COOKIE_DOMAIN")
+		if ok {
+			s.CookieDomain = cookieDomain
+		}
+		cookieSecure, ok := os.LookupEnv("COOKIE_SECURE")
+		if ok {
+			s.CookieSecure = mustParseBool("COOKIE_SECURE", cookieSecure)
+		}
+		cookieSameSite, ok := os.LookupEnv("COOKIE_SAME_SITE")
+		if ok {
+			s.CookieSameSite = cookieSameSite
+		}
+		tokenTTL, ok := os.LookupEnv("TOKEN_TTL")
+		if ok {
+			s.TokenTTL = mustParseDuration("TOKEN_TTL", tokenTTL)
+		}
+		tokenRefresh, ok := os.LookupEnv("TOKEN_REFRESH_BEFORE")
+		if ok {
+			s.TokenRefreshBefore = mustParseDuration("TOKEN_REFRESH_BEFORE", tokenRefresh)
+		}
+		oidcIssuer, ok := os.LookupEnv("OIDC_ISSUER")
+		if ok {
+			s.OIDCIssuer = oidcIssuer
+		}
+		oidcClientID, ok := os.LookupEnv("OIDC_CLIENT_ID")
+		if ok {
+			s.OIDCClientID = oidcClientID
+		}
+		oidcClientSecret, ok := os.LookupEnv("OIDC_CLIENT_SECRET")
+		if ok {
+			s.OIDCClientSecret = oidcClientSecret
+		}
+		oidcRedirectURL, ok := os.LookupEnv("OIDC_REDIRECT_URL")
+		if ok {
+			s.OIDCRedirectURL = oidcRedirectURL
+		}
+		oidcScopes, ok := os.LookupEnv("OIDC_SCOPES")
+		if ok {
+			s.OIDCScopes = oidcScopes
+		} else {
+			s.OIDCScopes = "openid email profile"
+		}
+		adminLogins, ok := os.LookupEnv("ADMIN_LOGINS")
+		if ok {
+			s.AdminLogins = adminLogins
+		}
+		trustedSubnet, ok := os.LookupEnv("TRUSTED_SUBNET")
+		if ok {
+			s.TrustedSubnet = trustedSubnet
+		}
+		deletedRetention, ok := os.LookupEnv("DELETED_RETENTION")
+		if ok {
+			s.DeletedRetention = mustParseDuration("DELETED_RETENTION", deletedRetention)
+		}
+		deletedPurgeSchedule, ok := os.LookupEnv("DELETED_PURGE_SCHEDULE")
+		if ok {
+			s.DeletedPurgeSchedule = deletedPurgeSchedule
+		}
+		instanceID, ok := os.LookupEnv("INSTANCE_ID")
+		if ok {
+			s.InstanceID = instanceID
+		}
+		cacheSize, ok := os.LookupEnv("CACHE_SIZE")
+		if ok {
+			s.CacheSize = mustParseInt("CACHE_SIZE", cacheSize)
+		}
+		cacheTTL, ok := os.LookupEnv("CACHE_TTL")
+		if ok {
+			s.CacheTTL = mustParseDuration("CACHE_TTL", cacheTTL)
+		}
+		cacheNegativeTTL, ok := os.LookupEnv("CACHE_NEGATIVE_TTL")
+		if ok {
+			s.CacheNegativeTTL = mustParseDuration("CACHE_NEGATIVE_TTL", cacheNegativeTTL)
+		}
+		batchMaxSize, ok := os.LookupEnv("BATCH_MAX_SIZE")
+		if ok {
+			s.BatchMaxSize = mustParseInt("BATCH_MAX_SIZE", batchMaxSize)
+		}
+		batchStreamChunk, ok := os.LookupEnv("BATCH_STREAM_CHUNK")
+		if ok {
+			s.BatchStreamChunk = mustParseInt("BATCH_STREAM_CHUNK", batchStreamChunk)
+		}
+		idempotencyTTL, ok := os.LookupEnv("IDEMPOTENCY_TTL")
+		if ok {
+			s.IdempotencyTTL = mustParseDuration("IDEMPOTENCY_TTL", idempotencyTTL)
+		}
+		redirectStatus, ok := os.LookupEnv("REDIRECT_STATUS")
+		if ok {
+			s.RedirectStatus = mustParseInt("REDIRECT_STATUS", redirectStatus)
+		}
+		redirectCacheControl, ok := os.LookupEnv("REDIRECT_CACHE_CONTROL")
+		if ok {
+			s.RedirectCacheControl = redirectCacheControl
+		}
+		previewExternal, ok := os.LookupEnv("PREVIEW_EXTERNAL")
+		if ok {
+			s.PreviewExternal = mustParseBool("PREVIEW_EXTERNAL", previewExternal)
+		}
+		dedupScope, ok := os.LookupEnv("DEDUP_SCOPE")
+		if ok {
+			s.DedupScope = dedupScope
+		}
+		dBMaxConns, ok := os.LookupEnv("DB_MAX_CONNS")
+		if ok {
+			s.DBMaxConns = mustParseInt("DB_MAX_CONNS", dBMaxConns)
+		}
+		dBMinConns, ok := os.LookupEnv("DB_MIN_CONNS")
+		if ok {
+			s.DBMinConns = mustParseInt("DB_MIN_CONNS", dBMinConns)
+		}
+		dBMaxConnLifetime, ok := os.LookupEnv("DB_MAX_CONN_LIFETIME")
+		if ok {
+			s.DBMaxConnLifetime = mustParseDuration("DB_MAX_CONN_LIFETIME", dBMaxConnLifetime)
+		}
+		dBMaxConnIdleTime, ok := os.LookupEnv("DB_MAX_CONN_IDLE_TIME")
+		if ok {
+			s.DBMaxConnIdleTime = mustParseDuration("DB_MAX_CONN_IDLE_TIME", dBMaxConnIdleTime)
+		}
+		dBHealthCheckPeriod, ok := os.LookupEnv("DB_HEALTH_CHECK_PERIOD")
+		if ok {
+			s.DBHealthCheckPeriod = mustParseDuration("DB_HEALTH_CHECK_PERIOD", dBHealthCheckPeriod)
+		}
+		dBStatementCache, ok := os.LookupEnv("DB_STATEMENT_CACHE")
+		if ok {
+			s.DBStatementCache = mustParseInt("DB_STATEMENT_CACHE", dBStatementCache)
+		}
+		dBQueryTimeout, ok := os.LookupEnv("DB_QUERY_TIMEOUT")
+		if ok {
+			s.DBQueryTimeout = mustParseDuration("DB_QUERY_TIMEOUT", dBQueryTimeout)
+		}
 	})
 
 }
+
+func mustParseBool(name, value string) bool {
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		panic(fmt.Errorf("invalid env %s: %w", name, err))
+	}
+	return b
+}
+
+func mustParseInt(name, value string) int {
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		panic(fmt.Errorf("invalid env %s: %w", name, err))
+	}
+	return n
+}
+
+func mustParseDuration(name, value string) time.Duration {
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		panic(fmt.Errorf("invalid env %s: %w", name, err))
+	}
+	return d
+}
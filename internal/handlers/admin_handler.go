@@ -0,0 +1,191 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hollgett/shortener.git/internal/models"
+	"github.com/hollgett/shortener.git/internal/service"
+	"go.uber.org/zap"
+)
+
+const adminPath = "/api/admin"
+
+// ControllerAdmin call function dependent on request method and path, available only for admins.
+//
+// "/api/admin/urls" GET search by query short, original, user, limit;
+// "/api/admin/urls/{short}" GET link, PATCH disable or reassign, DELETE hard delete;
+// "/api/admin/users/{id}/urls" GET links of user;
+// "/api/admin/audit" GET audit log by query from, to (RFC 3339), actor, short, action, limit;
+// "/api/admin/delete-jobs" GET deletes failed after all attempts by query limit.
+func (h *Handlers) ControllerAdmin(w http.ResponseWriter, r *http.Request) {
+	user, err := parseAuthorizedUser(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed parse userID: %s", err.Error()), http.StatusUnauthorized)
+		return
+	}
+	// api keys are scoped to own links
+	if len(user.APIKeyID) != 0 {
+		http.Error(w, "not allowed with api key", http.StatusForbidden)
+		return
+	}
+
+	segments := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, adminPath), "/"), "/")
+	switch {
+	case len(segments) == 1 && segments[0] == "urls" && r.Method == http.MethodGet:
+		h.searchAdminURLs(w, r, user, r.URL.Query().Get("user"))
+	case len(segments) == 2 && segments[0] == "urls":
+		h.controllerAdminURL(w, r, user, segments[1])
+	case len(segments) == 3 && segments[0] == "users" && segments[2] == "urls" && r.Method == http.MethodGet:
+		h.searchAdminURLs(w, r, user, segments[1])
+	case len(segments) == 1 && segments[0] == "audit" && r.Method == http.MethodGet:
+		h.getAuditRecords(w, r, user)
+	case len(segments) == 1 && segments[0] == "delete-jobs" && r.Method == http.MethodGet:
+		h.getDeadDeleteJobs(w, r, user)
+	case len(segments) == 1 && (segments[0] == "urls" || segments[0] == "audit" || segments[0] == "delete-jobs"),
+		len(segments) == 3 && segments[0] == "users" && segments[2] == "urls":
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (h *Handlers) controllerAdminURL(w http.ResponseWriter, r *http.Request, user User, short string) {
+	switch r.Method {
+	case http.MethodGet:
+		URL, err := h.service.GetURL(user.ID, short)
+		if err != nil {
+			h.writeAdminError(w, "GetURL", err)
+			return
+		}
+		h.writeJSON(w, URL, http.StatusOK)
+	case http.MethodPatch:
+		var req models.AdminURLUpdate
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, fmt.Sprintf("failed decode body: %s", err.Error()), http.StatusBadRequest)
+			return
+		}
+		URL, err := h.service.ModerateURL(newActor(r, user), short, req)
+		if err != nil {
+			h.writeAdminError(w, "ModerateURL", err)
+			return
+		}
+		h.writeJSON(w, URL, http.StatusOK)
+	case http.MethodDelete:
+		if err := h.service.DeleteURL(newActor(r, user), short); err != nil {
+			h.writeAdminError(w, "DeleteURL", err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (h *Handlers) searchAdminURLs(w http.ResponseWriter, r *http.Request, user User, userID string) {
+	query := r.URL.Query()
+	limit, err := parseLimit(query.Get("limit"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	URLs, err := h.service.SearchURLs(user.ID, models.AdminURLFilter{
+		ShortURL:    query.Get("short"),
+		OriginalURL: query.Get("original"),
+		UserID:      userID,
+		Limit:       limit,
+	})
+	if err != nil {
+		h.writeAdminError(w, "SearchURLs", err)
+		return
+	}
+	h.writeJSON(w, URLs, http.StatusOK)
+}
+
+func (h *Handlers) getAuditRecords(w http.ResponseWriter, r *http.Request, user User) {
+	query := r.URL.Query()
+	filter := models.AuditFilter{
+		ActorID:  query.Get("actor"),
+		ShortURL: query.Get("short"),
+		Action:   query.Get("action"),
+	}
+	var err error
+	if filter.Limit, err = parseLimit(query.Get("limit")); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if filter.From, err = parseTime(query.Get("from")); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if filter.To, err = parseTime(query.Get("to")); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	records, err := h.service.GetAuditRecords(user.ID, filter)
+	if err != nil {
+		h.writeAdminError(w, "GetAuditRecords", err)
+		return
+	}
+	h.writeJSON(w, records, http.StatusOK)
+}
+
+func (h *Handlers) getDeadDeleteJobs(w http.ResponseWriter, r *http.Request, user User) {
+	limit, err := parseLimit(r.URL.Query().Get("limit"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	jobs, err := h.service.GetDeadDeleteJobs(user.ID, limit)
+	if err != nil {
+		h.writeAdminError(w, "GetDeadDeleteJobs", err)
+		return
+	}
+	h.writeJSON(w, jobs, http.StatusOK)
+}
+
+// parse optional limit query param, zero means default of service
+func parseLimit(value string) (int, error) {
+	if len(value) == 0 {
+		return 0, nil
+	}
+	limit, err := strconv.Atoi(value)
+	if err != nil || limit < 0 {
+		return 0, fmt.Errorf("invalid limit: %s", value)
+	}
+	return limit, nil
+}
+
+// parse optional time query param in RFC 3339, empty value is zero time
+func parseTime(value string) (time.Time, error) {
+	if len(value) == 0 {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %s, RFC 3339 expected", value)
+	}
+	return t, nil
+}
+
+// map admin service errors to status codes
+func (h *Handlers) writeAdminError(w http.ResponseWriter, operation string, err error) {
+	switch {
+	case errors.Is(err, service.ErrNotAdmin):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, service.ErrURLNotExists):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, service.ErrInvalidAdminData):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		h.logger.Info(operation+" service", zap.Error(err))
+		http.Error(w, fmt.Sprintf("service error: %s", err.Error()), http.StatusInternalServerError)
+	}
+}
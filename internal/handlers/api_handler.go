@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"mime"
 	"net/http"
 
 	"github.com/hollgett/shortener.git/internal/models"
@@ -20,11 +21,15 @@ func (h *Handlers) CreateAPIShortURL(w http.ResponseWriter, r *http.Request) {
 	}
 
 	//get user id
-	user, err := parseUserID(r)
+	user, err := h.parseCreatingUser(w, r)
 	if err != nil {
 		http.Error(w, fmt.Sprintf("parse user id error: %s", err.Error()), http.StatusInternalServerError)
 		return
 	}
+	if !user.HasScope(service.ScopeLinksWrite) {
+		http.Error(w, "api key scope links:write required", http.StatusForbidden)
+		return
+	}
 
 	//read body and encode request json
 	req, err := io.ReadAll(r.Body)
@@ -42,9 +47,12 @@ func (h *Handlers) CreateAPIShortURL(w http.ResponseWriter, r *http.Request) {
 
 	//service logic
 	var statusCode int
-	s, err := h.service.CreateShortURL(user.ID, originalURL.URL)
+	s, err := h.service.CreateShortURL(r.Context(), newActor(r, user), originalURL.URL, originalURL.Title, originalURL.RedirectPolicy)
 	if err != nil && errors.Is(err, service.ErrShortExists) {
 		statusCode = http.StatusConflict
+	} else if err != nil && (errors.Is(err, service.ErrInvalidRedirect) || errors.Is(err, service.ErrInvalidTitle)) {
+		http.Error(w, fmt.Sprintf("service error: %s", err.Error()), http.StatusBadRequest)
+		return
 	} else if err != nil {
 		h.logger.Info("CreateShortURL service", zap.Error(err))
 		http.Error(w, fmt.Sprintf("service error: %s", err.Error()), http.StatusInternalServerError)
@@ -76,15 +84,27 @@ func (h *Handlers) CreateAPIShortURLs(w http.ResponseWriter, r *http.Request) {
 	}
 
 	//get user id
-	user, err := parseUserID(r)
+	user, err := h.parseCreatingUser(w, r)
 	if err != nil {
 		http.Error(w, fmt.Sprintf("parse user id error: %s", err.Error()), http.StatusInternalServerError)
 		return
 	}
+	if !user.HasScope(service.ScopeLinksWrite) {
+		http.Error(w, "api key scope links:write required", http.StatusForbidden)
+		return
+	}
+
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType == ndjsonContentType {
+		h.createAPIShortURLsStream(w, r, user)
+		return
+	}
 
 	// decode request json and call service logic
-	var requestURLs []models.BatchShortenerRequest
-	if err := json.NewDecoder(r.Body).Decode(&requestURLs); err != nil {
+	requestURLs, err := decodeBatch(r.Body, h.batch.MaxSize)
+	if errors.Is(err, errBatchTooLarge) {
+		http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+		return
+	} else if err != nil {
 		h.logger.Info("decoder request", zap.Error(err))
 		http.Error(w, fmt.Sprintf("failed decode body create batch urls: %s", err.Error()), http.StatusBadRequest)
 		return
@@ -95,7 +115,7 @@ func (h *Handlers) CreateAPIShortURLs(w http.ResponseWriter, r *http.Request) {
 	for i := range requestURLs {
 		originalURLs[i] = requestURLs[i].OriginalURL
 	}
-	shortURLs, err := h.service.CreateShortURLs(user.ID, originalURLs)
+	shortURLs, err := h.service.CreateShortURLs(r.Context(), newActor(r, user), originalURLs)
 	if err != nil {
 		h.logger.Info("service CreateShortURLs", zap.Error(err))
 		http.Error(w, fmt.Sprintf("service error: %s", err.Error()), http.StatusInternalServerError)
@@ -107,7 +127,8 @@ func (h *Handlers) CreateAPIShortURLs(w http.ResponseWriter, r *http.Request) {
 	for i := range responseURLs {
 		responseURLs[i] = models.BatchShortenerResponse{
 			CorrelationID: requestURLs[i].CorrelationID,
-			ShortURL:      fmt.Sprintf("%s/%s", h.baseURL, shortURLs[i]),
+			ShortURL:      fmt.Sprintf("%s/%s", h.baseURL, shortURLs[i].ShortURL),
+			Conflict:      shortURLs[i].Conflict,
 		}
 	}
 	resp, err := json.Marshal(responseURLs)
@@ -136,9 +157,13 @@ func (h *Handlers) ControllerUserURLs(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handlers) GetAPIUserURLs(w http.ResponseWriter, r *http.Request) {
-	user, err := parseUserID(r)
-	if err != nil || user.Err != nil {
-		http.Error(w, fmt.Sprintf("failed parse userID: %s", user.Err.Error()), http.StatusUnauthorized)
+	user, err := parseAuthorizedUser(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed parse userID: %s", err.Error()), http.StatusUnauthorized)
+		return
+	}
+	if !user.HasScope(service.ScopeLinksRead) {
+		http.Error(w, "api key scope links:read required", http.StatusForbidden)
 		return
 	}
 
@@ -172,9 +197,14 @@ func (h *Handlers) GetAPIUserURLs(w http.ResponseWriter, r *http.Request) {
 
 func (h *Handlers) DeleteAPIUserURLs(w http.ResponseWriter, r *http.Request) {
 	//read body and unmarshal request data
-	user, err := parseUserID(r)
+	user, err := parseAuthorizedUser(r)
 	if err != nil {
 		http.Error(w, fmt.Sprintf("delete user urls error: %s", err.Error()), http.StatusUnauthorized)
+		return
+	}
+	if !user.HasScope(service.ScopeLinksDelete) {
+		http.Error(w, "api key scope links:delete required", http.StatusForbidden)
+		return
 	}
 
 	reqData, err := io.ReadAll(r.Body)
@@ -190,9 +220,17 @@ func (h *Handlers) DeleteAPIUserURLs(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	go h.service.DeleteUserURLs(user.ID, deleteURLs)
+	operation, err := h.service.DeleteUserURLs(newActor(r, user), deleteURLs)
+	if err != nil {
+		h.logger.Info("DeleteAPIUserURLs service", zap.Error(err))
+		http.Error(w, fmt.Sprintf("failed delete user URLs: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
 
 	h.logger.Info("DeleteAPIUserURLs GET", zap.Any("data", deleteURLs))
-	w.Header().Add("Content-Type", "application/json")
-	w.WriteHeader(http.StatusAccepted)
+	w.Header().Set("Location", operationsPath+operation.ID)
+	h.writeJSON(w, models.DeleteOperationResponse{
+		OperationID: operation.ID,
+		Status:      operation.Status,
+	}, http.StatusAccepted)
 }
@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/hollgett/shortener.git/internal/models"
+	"github.com/hollgett/shortener.git/internal/service"
+	"go.uber.org/zap"
+)
+
+const apiKeysPath = "/api/user/keys"
+
+// ControllerAPIKeys call function dependent on request method and path.
+//
+// "/api/user/keys" POST create key, GET list keys; "/api/user/keys/{id}" DELETE revoke key.
+func (h *Handlers) ControllerAPIKeys(w http.ResponseWriter, r *http.Request) {
+	user, err := parseAuthorizedUser(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed parse userID: %s", err.Error()), http.StatusUnauthorized)
+		return
+	}
+	// keys can't manage keys
+	if len(user.APIKeyID) != 0 {
+		http.Error(w, "not allowed with api key", http.StatusForbidden)
+		return
+	}
+
+	keyID := strings.Trim(strings.TrimPrefix(r.URL.Path, apiKeysPath), "/")
+	switch {
+	case len(keyID) == 0 && r.Method == http.MethodPost:
+		h.createAPIKey(w, r, user)
+	case len(keyID) == 0 && r.Method == http.MethodGet:
+		h.getAPIKeys(w, user)
+	case len(keyID) != 0 && r.Method == http.MethodDelete:
+		h.revokeAPIKey(w, user, keyID)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (h *Handlers) createAPIKey(w http.ResponseWriter, r *http.Request, user User) {
+	var req models.APIKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Info("createAPIKey decode", zap.Error(err))
+		http.Error(w, fmt.Sprintf("failed decode body: %s", err.Error()), http.StatusBadRequest)
+		return
+	}
+
+	key, plainKey, err := h.service.CreateAPIKey(user.ID, req.Name, req.Scopes, req.ExpiresAt)
+	if err != nil && errors.Is(err, service.ErrInvalidAPIKeyData) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	} else if err != nil {
+		h.logger.Info("CreateAPIKey service", zap.Error(err))
+		http.Error(w, fmt.Sprintf("service error: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	resp := toAPIKeyResponse(key)
+	resp.Key = plainKey
+	h.writeJSON(w, resp, http.StatusCreated)
+}
+
+func (h *Handlers) getAPIKeys(w http.ResponseWriter, user User) {
+	keys, err := h.service.GetUserAPIKeys(user.ID)
+	if err != nil {
+		h.logger.Info("GetUserAPIKeys service", zap.Error(err))
+		http.Error(w, fmt.Sprintf("service error: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	resp := make([]models.APIKeyResponse, len(keys))
+	for i, key := range keys {
+		resp[i] = toAPIKeyResponse(key)
+	}
+	h.writeJSON(w, resp, http.StatusOK)
+}
+
+func (h *Handlers) revokeAPIKey(w http.ResponseWriter, user User, keyID string) {
+	err := h.service.RevokeAPIKey(user.ID, keyID)
+	if err != nil && errors.Is(err, service.ErrAPIKeyNotExists) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	} else if err != nil {
+		h.logger.Info("RevokeAPIKey service", zap.Error(err))
+		http.Error(w, fmt.Sprintf("service error: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func toAPIKeyResponse(key models.APIKey) models.APIKeyResponse {
+	return models.APIKeyResponse{
+		ID:         key.ID,
+		Name:       key.Name,
+		Scopes:     key.Scopes,
+		CreatedAt:  key.CreatedAt,
+		LastUsedAt: key.LastUsedAt,
+		ExpiresAt:  key.ExpiresAt,
+		Revoked:    key.Revoked,
+	}
+}
@@ -6,32 +6,53 @@ import (
 	"fmt"
 	"math/rand"
 	"net/http"
+	"slices"
+	"strings"
 	"time"
 
-	"github.com/golang-jwt/jwt/v5"
+	"github.com/hollgett/shortener.git/internal/models"
+	"github.com/hollgett/shortener.git/internal/service"
 	"go.uber.org/zap"
 )
 
 const (
-	token_exp         = 31 * (24 * time.Hour)
-	len_user_id       = 8
-	token_user_cookie = "uid"
+	token_exp           = 31 * (24 * time.Hour)
+	token_refresh       = 7 * (24 * time.Hour)
+	len_user_id         = 8
+	token_user_cookie   = "uid"
+	authorization_token = "Bearer "
 )
 
 var (
-	ErrNoCookie error = errors.New("cookie not found")
+	ErrNoCookie       error = errors.New("cookie not found")
+	ErrInvalidToken   error = errors.New("invalid auth token")
+	ErrSessionRevoked error = errors.New("session revoked")
 )
 
 type ctxKey string
 
-type Claims struct {
-	jwt.RegisteredClaims
-	UserID string
+type User struct {
+	ID string
+	// session of registered user, empty for anonymous user
+	SessionID string
+	// set if request authenticated by api key, access limited by Scopes
+	APIKeyID string
+	Scopes   []string
+	Err      error
 }
 
-type User struct {
-	ID  string
-	Err error
+// HasScope return true if user authenticated by token or api key has scope
+func (u User) HasScope(scope string) bool {
+	if len(u.APIKeyID) == 0 {
+		return true
+	}
+	return slices.Contains(u.Scopes, scope)
+}
+
+// IdentityChecker check sessions of registered users and api keys
+type IdentityChecker interface {
+	IsSessionActive(sessionID string) (bool, error)
+	AuthenticateAPIKey(plainKey string) (models.APIKey, error)
 }
 
 var (
@@ -39,71 +60,106 @@ var (
 	pseudoRand *rand.Rand = rand.New(rand.NewSource(time.Now().Unix()))
 )
 
+// AuthMiddleware take user token or api key from "Authorization: Bearer" header or token from "uid" cookie.
+//
+// without token new user is created and cookie set. Invalid cookie is kept and context has user without id
+// and ErrInvalidToken, handlers which require identity return 401 and only create paths issue new user.
+// Invalid bearer token is rejected with 401. Tokens near expiry or signed with previous key are re-issued.
 func (m *Middleware) AuthMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if tokenStr, ok := bearerToken(r); ok && strings.HasPrefix(tokenStr, service.APIKeyPrefix) {
+			key, err := m.identities.AuthenticateAPIKey(tokenStr)
+			if err != nil {
+				m.logger.Info("authenticate api key", zap.Error(err))
+				http.Error(w, fmt.Sprintf("invalid api key: %s", err.Error()), http.StatusUnauthorized)
+				return
+			}
+			next.ServeHTTP(w, SetContext(r, User{ID: key.UserID, APIKeyID: key.ID, Scopes: key.Scopes}))
+			return
+		} else if ok {
+			claims, current, err := m.verifyToken(tokenStr)
+			if err != nil {
+				m.logger.Info("parse bearer token", zap.Error(err))
+				http.Error(w, fmt.Sprintf("invalid token: %s", err.Error()), http.StatusUnauthorized)
+				return
+			}
+			if m.tokens.NeedRefresh(claims, current) {
+				token, err := m.tokens.Build(claims.UserID, claims.SessionID)
+				if err != nil {
+					m.logger.Info("refresh bearer token", zap.Error(err))
+				} else {
+					w.Header().Set("Authorization", authorization_token+token)
+				}
+			}
+			next.ServeHTTP(w, SetContext(r, User{ID: claims.UserID, SessionID: claims.SessionID}))
+			return
+		}
+
 		cookie, err := r.Cookie(token_user_cookie)
 		if err != nil {
 			m.logger.Info("get cookie", zap.Error(err))
-			userID, errCook := m.SetUserCookie(w)
-			if errCook != nil {
-				m.logger.Info("set user cookie", zap.Error(err))
-				http.Error(w, fmt.Sprintf("set user cookie: %s", err.Error()), http.StatusInternalServerError)
-				return
-			}
-			next.ServeHTTP(w, SetContext(r, userID, ErrNoCookie))
+			m.serveNewUser(w, r, next, ErrNoCookie)
 			return
 		}
-		userID, err := m.GetUserID(cookie.Value)
+		claims, current, err := m.verifyToken(cookie.Value)
 		if err != nil {
 			m.logger.Info("get user ID", zap.Error(err))
-			userID, errCook := m.SetUserCookie(w)
-			if errCook != nil {
-				m.logger.Info("set user cookie", zap.Error(err))
-				http.Error(w, fmt.Sprintf("set user cookie: %s", err.Error()), http.StatusInternalServerError)
-				return
-			}
-			next.ServeHTTP(w, SetContext(r, userID, nil))
+			next.ServeHTTP(w, SetContext(r, User{Err: fmt.Errorf("%w: %w", ErrInvalidToken, err)}))
 			return
 		}
-		next.ServeHTTP(w, SetContext(r, userID, nil))
+		if m.tokens.NeedRefresh(claims, current) {
+			// token signed with previous key or near expiry, re-issue it with same user
+			if _, err := m.tokens.SetCookie(w, claims.UserID, claims.SessionID); err != nil {
+				m.logger.Info("re-issue user cookie", zap.Error(err))
+			}
+		}
+		next.ServeHTTP(w, SetContext(r, User{ID: claims.UserID, SessionID: claims.SessionID}))
 	})
 }
 
-func (m *Middleware) buildJWTString() (token string, userID string, err error) {
-	userID = generateUserID()
-	tokenJWT := jwt.NewWithClaims(jwt.SigningMethodHS256, Claims{
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(token_exp)),
-		},
-		UserID: userID,
-	})
-
-	token, err = tokenJWT.SignedString([]byte(m.secretKey))
+// verify token signature and session of registered user
+func (m *Middleware) verifyToken(tokenStr string) (*Claims, bool, error) {
+	claims, current, err := m.tokens.Parse(tokenStr)
 	if err != nil {
-		err = fmt.Errorf("failed signing token: %w", err)
-		return
+		return nil, false, err
+	}
+	if len(claims.SessionID) == 0 {
+		return claims, current, nil
 	}
-	return
+	active, err := m.identities.IsSessionActive(claims.SessionID)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed check session: %w", err)
+	}
+	if !active {
+		return nil, false, ErrSessionRevoked
+	}
+	return claims, current, nil
 }
 
-func (m *Middleware) GetUserID(tokenStr string) (string, error) {
-	claims := &Claims{}
-
-	token, err := jwt.ParseWithClaims(tokenStr, claims,
-		func(t *jwt.Token) (interface{}, error) {
-			if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, fmt.Errorf("unexpected signing method: %v", t.Header["alg"])
-			}
-			return []byte(m.secretKey), nil
-		})
+// serve request with new user and cookie, userErr keep reason why user was created
+func (m *Middleware) serveNewUser(w http.ResponseWriter, r *http.Request, next http.Handler, userErr error) {
+	userID, err := m.SetUserCookie(w)
 	if err != nil {
-		return "", fmt.Errorf("failed parse token: %w", err)
+		m.logger.Info("set user cookie", zap.Error(err))
+		http.Error(w, fmt.Sprintf("set user cookie: %s", err.Error()), http.StatusInternalServerError)
+		return
 	}
+	next.ServeHTTP(w, SetContext(r, User{ID: userID, Err: userErr}))
+}
 
-	if !token.Valid {
-		return "", errors.New("token is not valid")
+func bearerToken(r *http.Request) (string, bool) {
+	header := r.Header.Get("Authorization")
+	if len(header) <= len(authorization_token) || !strings.EqualFold(header[:len(authorization_token)], authorization_token) {
+		return "", false
 	}
+	return strings.TrimSpace(header[len(authorization_token):]), true
+}
 
+func (m *Middleware) GetUserID(tokenStr string) (string, error) {
+	claims, _, err := m.verifyToken(tokenStr)
+	if err != nil {
+		return "", err
+	}
 	return claims.UserID, nil
 }
 
@@ -120,22 +176,13 @@ func generateUserID() string {
 }
 
 func (m *Middleware) SetUserCookie(w http.ResponseWriter) (string, error) {
-	token, userID, err := m.buildJWTString()
-	if err != nil {
-		return "", fmt.Errorf("failed build jwt: %w", err)
-	}
-	NewCookie := &http.Cookie{
-		Name:  token_user_cookie,
-		Value: token,
-		Path:  "/",
+	userID := generateUserID()
+	if _, err := m.tokens.SetCookie(w, userID, ""); err != nil {
+		return "", err
 	}
-	http.SetCookie(w, NewCookie)
 	return userID, nil
 }
 
-func SetContext(r *http.Request, userID string, err error) *http.Request {
-	return r.WithContext(context.WithValue(r.Context(), UserKeyCtx, User{
-		ID:  userID,
-		Err: err,
-	}))
+func SetContext(r *http.Request, user User) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), UserKeyCtx, user))
 }
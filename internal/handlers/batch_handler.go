@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/hollgett/shortener.git/internal/models"
+	"go.uber.org/zap"
+)
+
+const ndjsonContentType = "application/x-ndjson"
+
+// max length of line in ndjson batch stream
+const maxStreamLineSize = 64 * 1024
+
+var errBatchTooLarge = errors.New("too many links in batch")
+
+// decode json array of batch by elements, decoding is stopped when array is longer than maxSize
+func decodeBatch(body io.Reader, maxSize int) ([]models.BatchShortenerRequest, error) {
+	dec := json.NewDecoder(body)
+	if token, err := dec.Token(); err != nil {
+		return nil, err
+	} else if token != json.Delim('[') {
+		return nil, errors.New("batch must be json array")
+	}
+
+	requestURLs := make([]models.BatchShortenerRequest, 0)
+	for dec.More() {
+		if maxSize > 0 && len(requestURLs) == maxSize {
+			return nil, fmt.Errorf("%w, max %d, use %s stream for larger batches", errBatchTooLarge, maxSize, ndjsonContentType)
+		}
+		var requestURL models.BatchShortenerRequest
+		if err := dec.Decode(&requestURL); err != nil {
+			return nil, err
+		}
+		requestURLs = append(requestURLs, requestURL)
+	}
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+	return requestURLs, nil
+}
+
+// shorten ndjson stream of batch requests by chunks, result of every line is written as soon as its chunk is shortened.
+//
+// broken lines are answered with error, stream is stopped on service error
+func (h *Handlers) createAPIShortURLsStream(w http.ResponseWriter, r *http.Request, user User) {
+	rc := http.NewResponseController(w)
+	// response is written while body is read
+	if err := rc.EnableFullDuplex(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		h.logger.Info("enable full duplex", zap.Error(err))
+	}
+	w.Header().Set("Content-Type", ndjsonContentType)
+	w.WriteHeader(http.StatusOK)
+	enc := json.NewEncoder(w)
+
+	chunkSize := h.batch.StreamChunk
+	if chunkSize <= 0 {
+		chunkSize = 1
+	}
+	actor := newActor(r, user)
+	chunk := make([]models.BatchShortenerRequest, 0, chunkSize)
+	// write results of chunk, return false if stream must be stopped
+	shorten := func() bool {
+		if len(chunk) == 0 {
+			return true
+		}
+		originalURLs := make([]string, len(chunk))
+		for i := range chunk {
+			originalURLs[i] = chunk[i].OriginalURL
+		}
+		shortURLs, err := h.service.CreateShortURLs(r.Context(), actor, originalURLs)
+		if err != nil {
+			h.logger.Info("service CreateShortURLs", zap.Error(err))
+			enc.Encode(models.BatchShortenerResponse{Error: fmt.Sprintf("service error: %s", err.Error())})
+			return false
+		}
+		for i, shortURL := range shortURLs {
+			if err := enc.Encode(models.BatchShortenerResponse{
+				CorrelationID: chunk[i].CorrelationID,
+				ShortURL:      fmt.Sprintf("%s/%s", h.baseURL, shortURL.ShortURL),
+				Conflict:      shortURL.Conflict,
+			}); err != nil {
+				h.logger.Info("write stream", zap.Error(err))
+				return false
+			}
+		}
+		rc.Flush()
+		chunk = chunk[:0]
+		return true
+	}
+
+	scanner := bufio.NewScanner(r.Body)
+	scanner.Buffer(make([]byte, 0, 4096), maxStreamLineSize)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var requestURL models.BatchShortenerRequest
+		if err := json.Unmarshal(scanner.Bytes(), &requestURL); err != nil {
+			enc.Encode(models.BatchShortenerResponse{Error: fmt.Sprintf("failed decode line: %s", err.Error())})
+			continue
+		}
+		if len(requestURL.OriginalURL) == 0 {
+			enc.Encode(models.BatchShortenerResponse{CorrelationID: requestURL.CorrelationID, Error: "original_url is empty"})
+			continue
+		}
+		chunk = append(chunk, requestURL)
+		if len(chunk) == chunkSize && !shorten() {
+			return
+		}
+	}
+	if !shorten() {
+		return
+	}
+	if err := scanner.Err(); err != nil {
+		h.logger.Info("read stream", zap.Error(err))
+		enc.Encode(models.BatchShortenerResponse{Error: fmt.Sprintf("failed read body: %s", err.Error())})
+	}
+}
@@ -17,6 +17,61 @@ func (g *gzipResponseWriter) Write(p []byte) (int, error) {
 	return g.Writer.Write(p)
 }
 
+// write compressed data to client, used by streaming handlers
+func (g *gzipResponseWriter) Flush() {
+	if flusher, ok := g.Writer.(interface{ Flush() error }); ok {
+		flusher.Flush()
+	}
+	http.NewResponseController(g.ResponseWriter).Flush()
+}
+
+func (g *gzipResponseWriter) Unwrap() http.ResponseWriter {
+	return g.ResponseWriter
+}
+
+// htmlGzipResponseWriter compress response only if handler replies html, used for requests without
+// compressible body such as preview page.
+type htmlGzipResponseWriter struct {
+	http.ResponseWriter
+	// nil until header is written or if response isn't compressed
+	gzip        *gzip.Writer
+	wroteHeader bool
+}
+
+func (g *htmlGzipResponseWriter) WriteHeader(statusCode int) {
+	if !g.wroteHeader {
+		g.wroteHeader = true
+		if strings.HasPrefix(g.Header().Get("Content-Type"), "text/html") &&
+			statusCode != http.StatusNoContent && statusCode != http.StatusNotModified {
+			g.Header().Del("Content-Length")
+			g.Header().Add("Content-Encoding", "gzip")
+			g.gzip = gzip.NewWriter(g.ResponseWriter)
+		}
+	}
+	g.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (g *htmlGzipResponseWriter) Write(p []byte) (int, error) {
+	if !g.wroteHeader {
+		g.WriteHeader(http.StatusOK)
+	}
+	if g.gzip != nil {
+		return g.gzip.Write(p)
+	}
+	return g.ResponseWriter.Write(p)
+}
+
+func (g *htmlGzipResponseWriter) Close() error {
+	if g.gzip != nil {
+		return g.gzip.Close()
+	}
+	return nil
+}
+
+func (g *htmlGzipResponseWriter) Unwrap() http.ResponseWriter {
+	return g.ResponseWriter
+}
+
 func (m *Middleware) Compress(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
@@ -33,6 +88,11 @@ func (m *Middleware) Compress(next http.Handler) http.Handler {
 				}, r)
 				return
 			}
+
+			htmlWriter := &htmlGzipResponseWriter{ResponseWriter: w}
+			defer htmlWriter.Close()
+			next.ServeHTTP(htmlWriter, r)
+			return
 		}
 
 		next.ServeHTTP(w, r)
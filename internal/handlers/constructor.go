@@ -4,36 +4,72 @@ import (
 	"net/http"
 
 	"github.com/hollgett/shortener.git/internal/logger"
+	"github.com/hollgett/shortener.git/internal/oidc"
 	"github.com/hollgett/shortener.git/internal/service"
 )
 
 type Handlers struct {
 	logger  *logger.Logger
 	service *service.Service
+	tokens  *TokenIssuer
+	// nil if single sign-on is not configured
+	oidc    *oidc.Provider
 	baseURL string
+	batch   BatchConfig
+	// defaults for links without own redirect policy
+	redirect RedirectConfig
+}
+
+// BatchConfig limits of batch shortening
+type BatchConfig struct {
+	// max count of links in json array batch, zero is unlimited
+	MaxSize int
+	// count of links shortened at once in ndjson stream
+	StreamChunk int
+}
+
+// RedirectConfig global redirect policy, used for fields which are not set on link
+type RedirectConfig struct {
+	// 301, 302, 307 or 308
+	Status int
+	// Cache-Control of redirect, empty means header is not sent
+	CacheControl string
+	// links to other domains than base url always open preview page
+	PreviewExternal bool
 }
 
 type middlewareConv func(http.Handler) http.Handler
 
 type Middleware struct {
-	logger    *logger.Logger
-	secretKey string
+	logger     *logger.Logger
+	tokens     *TokenIssuer
+	identities IdentityChecker
+	// responses of create requests by idempotency key
+	idempotency IdempotencyKeeper
 }
 
 // build handlers
-func NewHandlers(logger *logger.Logger, service *service.Service, baseURL string) *Handlers {
+func NewHandlers(logger *logger.Logger, service *service.Service, tokens *TokenIssuer, oidc *oidc.Provider, baseURL string, batch BatchConfig,
+	redirect RedirectConfig) *Handlers {
 	return &Handlers{
 		logger:  logger,
 		service: service,
+		tokens:  tokens,
+		oidc:    oidc,
 		baseURL: baseURL,
+		batch:   batch,
+
+		redirect: redirect,
 	}
 }
 
 // build new handler with middleware
-func NewMiddleware(logger *logger.Logger, secretKey string) *Middleware {
+func NewMiddleware(logger *logger.Logger, tokens *TokenIssuer, identities IdentityChecker, idempotency IdempotencyKeeper) *Middleware {
 	return &Middleware{
-		logger:    logger,
-		secretKey: secretKey,
+		logger:      logger,
+		tokens:      tokens,
+		identities:  identities,
+		idempotency: idempotency,
 	}
 }
 
@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+
+	"github.com/hollgett/shortener.git/internal/models"
+	"github.com/hollgett/shortener.git/internal/service"
+	"go.uber.org/zap"
+)
+
+const (
+	idempotencyKeyHeader      = "Idempotency-Key"
+	idempotentReplayedHeader  = "Idempotent-Replayed"
+	maxIdempotencyKeyLength   = 255
+	maxIdempotentRequestBytes = 10 << 20
+)
+
+// IdempotencyKeeper save and replay responses by idempotency key of user
+type IdempotencyKeeper interface {
+	IdempotencyEnabled() bool
+	BeginIdempotent(userID, key, requestHash string) (models.IdempotencyRecord, bool, error)
+	CompleteIdempotent(record models.IdempotencyRecord, status int, contentType string, body []byte) error
+	ReleaseIdempotent(record models.IdempotencyRecord) error
+}
+
+// response writer which keeps copy of response for replay
+type recordResponseWriter struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (rw *recordResponseWriter) WriteHeader(statusCode int) {
+	if rw.status == 0 {
+		rw.status = statusCode
+	}
+	rw.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (rw *recordResponseWriter) Write(data []byte) (int, error) {
+	if rw.status == 0 {
+		rw.status = http.StatusOK
+	}
+	rw.body.Write(data)
+	return rw.ResponseWriter.Write(data)
+}
+
+func (rw *recordResponseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
+// Idempotent replay first response of create request for repeats with same Idempotency-Key header of user.
+//
+// ndjson streams are not saved, their responses are unbounded
+func (m *Middleware) Idempotent(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(idempotencyKeyHeader)
+		mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		if len(key) == 0 || r.Method != http.MethodPost || mediaType == ndjsonContentType || !m.idempotency.IdempotencyEnabled() {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			http.Error(w, fmt.Sprintf("idempotency key is longer than %d", maxIdempotencyKeyLength), http.StatusBadRequest)
+			return
+		}
+		// user without valid token has no keys, new user is issued by handler
+		user, err := parseUserID(r)
+		if err != nil || len(user.ID) == 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxIdempotentRequestBytes))
+		if err != nil {
+			http.Error(w, fmt.Sprintf("failed read body: %s", err.Error()), http.StatusBadRequest)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		hash := sha256.New()
+		fmt.Fprintf(hash, "%s %s\n", r.Method, r.URL.Path)
+		hash.Write(body)
+
+		record, replay, err := m.idempotency.BeginIdempotent(user.ID, key, hex.EncodeToString(hash.Sum(nil)))
+		switch {
+		case errors.Is(err, service.ErrIdempotencyKeyMismatch):
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+			return
+		case errors.Is(err, service.ErrIdempotencyKeyInProgress):
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		case err != nil:
+			m.logger.Info("BeginIdempotent", zap.Error(err))
+			http.Error(w, fmt.Sprintf("failed check idempotency key: %s", err.Error()), http.StatusInternalServerError)
+			return
+		case replay:
+			if record.ContentType != "" {
+				w.Header().Set("Content-Type", record.ContentType)
+			}
+			w.Header().Set(idempotentReplayedHeader, "true")
+			w.WriteHeader(record.Status)
+			w.Write(record.Body)
+			return
+		}
+
+		// key of panicked handler is released, otherwise retries get conflict until key expires
+		defer func() {
+			if p := recover(); p != nil {
+				if err := m.idempotency.ReleaseIdempotent(record); err != nil {
+					m.logger.Info("ReleaseIdempotent", zap.Error(err))
+				}
+				panic(p)
+			}
+		}()
+		recorder := &recordResponseWriter{ResponseWriter: w}
+		next.ServeHTTP(recorder, r)
+		if recorder.status == 0 {
+			recorder.status = http.StatusOK
+		}
+		err = m.idempotency.CompleteIdempotent(record, recorder.status, w.Header().Get("Content-Type"), recorder.body.Bytes())
+		if err != nil {
+			m.logger.Info("CompleteIdempotent", zap.Error(err))
+		}
+	})
+}
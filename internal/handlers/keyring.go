@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	ErrUnknownKeyID error = errors.New("unknown signing key id")
+)
+
+// SigningKey secret for signing auth tokens, ID is written to "kid" header of token
+type SigningKey struct {
+	ID     string
+	Secret []byte
+}
+
+// Keyring keep current signing key and previous keys which still accepted for verification.
+// Keys are set once on start, rotation is done by restart with new key list.
+type Keyring struct {
+	current  SigningKey
+	previous map[string]SigningKey
+}
+
+// NewKeyring build keyring with current key for signing and previous keys for verification only.
+func NewKeyring(current SigningKey, previous ...SigningKey) (*Keyring, error) {
+	if err := validateKey(current); err != nil {
+		return nil, fmt.Errorf("current key: %w", err)
+	}
+	k := &Keyring{
+		current:  current,
+		previous: make(map[string]SigningKey, len(previous)),
+	}
+	for _, key := range previous {
+		if err := validateKey(key); err != nil {
+			return nil, fmt.Errorf("previous key: %w", err)
+		}
+		if key.ID == current.ID {
+			return nil, fmt.Errorf("previous key id %q equal current key id", key.ID)
+		}
+		k.previous[key.ID] = key
+	}
+	return k, nil
+}
+
+func validateKey(key SigningKey) error {
+	if len(key.ID) == 0 {
+		return errors.New("empty key id")
+	}
+	if len(key.Secret) == 0 {
+		return fmt.Errorf("empty secret for key id %q", key.ID)
+	}
+	return nil
+}
+
+// Current return key used for signing new tokens
+func (k *Keyring) Current() SigningKey {
+	return k.current
+}
+
+// Lookup return key by id, current flag is true if key is used for signing.
+func (k *Keyring) Lookup(kid string) (key SigningKey, current bool, err error) {
+	if kid == k.current.ID {
+		return k.current, true, nil
+	}
+	if key, ok := k.previous[kid]; ok {
+		return key, false, nil
+	}
+	return SigningKey{}, false, ErrUnknownKeyID
+}
+
+// Keys return all keys, current is first.
+func (k *Keyring) Keys() []SigningKey {
+	keys := make([]SigningKey, 0, len(k.previous)+1)
+	keys = append(keys, k.current)
+	for _, key := range k.previous {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// ParseSigningKeys parse keys in format "kid1:secret1,kid2:secret2".
+func ParseSigningKeys(src string) ([]SigningKey, error) {
+	keys := make([]SigningKey, 0)
+	for _, pair := range strings.Split(src, ",") {
+		pair = strings.TrimSpace(pair)
+		if len(pair) == 0 {
+			continue
+		}
+		kid, secret, ok := strings.Cut(pair, ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid key %q, expected kid:secret", pair)
+		}
+		key := SigningKey{
+			ID:     strings.TrimSpace(kid),
+			Secret: []byte(secret),
+		}
+		if err := validateKey(key); err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
@@ -9,9 +9,11 @@ import (
 
 func (m *Middleware) RequestLogged(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID, _ := r.Context().Value(RequestIDKeyCtx).(string)
 		m.logger.Info("Request",
 			zap.String("URI", r.URL.RequestURI()),
 			zap.String("method", r.Method),
+			zap.String("request id", requestID),
 		)
 
 		next.ServeHTTP(w, r)
@@ -36,6 +38,11 @@ func (a *aliasResponseWriter) Write(dataResp []byte) (int, error) {
 	return len, err
 }
 
+// let response controller reach flush and full duplex of original writer
+func (a *aliasResponseWriter) Unwrap() http.ResponseWriter {
+	return a.ResponseWriter
+}
+
 func (m *Middleware) ResponseLogged(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		now := time.Now()
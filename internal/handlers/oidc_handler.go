@@ -0,0 +1,187 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/hollgett/shortener.git/internal/oidc"
+	"github.com/hollgett/shortener.git/internal/service"
+	"go.uber.org/zap"
+)
+
+const (
+	oidc_state_cookie = "oidc_state"
+	oidc_state_exp    = 10 * time.Minute
+	len_oidc_random   = 32
+)
+
+// OIDCCallbackPath path of callback handler, used for default redirect url
+const OIDCCallbackPath = "/api/auth/oidc/callback"
+
+// state of authorization code flow kept in signed cookie between login and callback
+type oidcFlowClaims struct {
+	jwt.RegisteredClaims
+	State        string `json:"state"`
+	Nonce        string `json:"nonce"`
+	CodeVerifier string `json:"verifier"`
+}
+
+// LoginOIDC start authorization code flow with PKCE and redirect to identity provider.
+func (h *Handlers) LoginOIDC(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	if h.oidc == nil {
+		http.Error(w, "single sign-on is not configured", http.StatusNotFound)
+		return
+	}
+
+	flow := oidcFlowClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(oidc_state_exp)),
+		},
+		State:        oidc.RandomString(len_oidc_random),
+		Nonce:        oidc.RandomString(len_oidc_random),
+		CodeVerifier: oidc.RandomString(len_oidc_random),
+	}
+	authURL, err := h.oidc.AuthCodeURL(r.Context(), flow.State, flow.Nonce, flow.CodeVerifier)
+	if err != nil {
+		h.logger.Info("oidc auth code url", zap.Error(err))
+		http.Error(w, fmt.Sprintf("identity provider error: %s", err.Error()), http.StatusBadGateway)
+		return
+	}
+
+	if err := h.setOIDCFlowCookie(w, flow); err != nil {
+		h.logger.Info("oidc flow cookie", zap.Error(err))
+		http.Error(w, fmt.Sprintf("failed set state cookie: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+	http.Redirect(w, r, authURL, http.StatusFound)
+}
+
+// CallbackOIDC verify state, exchange code to id token and login user mapped to subject.
+//
+// logged in user get identity linked to his account, anonymous user URLs are claimed to account.
+func (h *Handlers) CallbackOIDC(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	if h.oidc == nil {
+		http.Error(w, "single sign-on is not configured", http.StatusNotFound)
+		return
+	}
+
+	user, err := h.parseCreatingUser(w, r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("parse user id error: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	query := r.URL.Query()
+	if errCode := query.Get("error"); len(errCode) != 0 {
+		http.Error(w, fmt.Sprintf("identity provider error: %s %s", errCode, query.Get("error_description")), http.StatusUnauthorized)
+		return
+	}
+
+	flow, err := h.parseOIDCFlowCookie(r)
+	if err != nil {
+		h.logger.Info("oidc flow cookie", zap.Error(err))
+		http.Error(w, fmt.Sprintf("invalid login state: %s", err.Error()), http.StatusBadRequest)
+		return
+	}
+	h.clearOIDCFlowCookie(w)
+	if subtle.ConstantTimeCompare([]byte(flow.State), []byte(query.Get("state"))) != 1 {
+		http.Error(w, "state mismatch", http.StatusBadRequest)
+		return
+	}
+
+	claims, err := h.oidc.Exchange(r.Context(), query.Get("code"), flow.CodeVerifier, flow.Nonce)
+	if err != nil && (errors.Is(err, oidc.ErrInvalidIDToken) || errors.Is(err, oidc.ErrNonceMismatch)) {
+		h.logger.Info("oidc exchange", zap.Error(err))
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	} else if err != nil {
+		h.logger.Info("oidc exchange", zap.Error(err))
+		http.Error(w, fmt.Sprintf("identity provider error: %s", err.Error()), http.StatusBadGateway)
+		return
+	}
+
+	email := ""
+	if claims.EmailVerified {
+		email = claims.Email
+	}
+	linkToCurrent := user.Err == nil && len(user.SessionID) != 0 && len(user.APIKeyID) == 0
+	account, session, err := h.service.LoginExternalUser(user.ID, linkToCurrent, h.oidc.Issuer(), claims.Subject, email)
+	if err != nil && (errors.Is(err, service.ErrAlreadyRegistered) || errors.Is(err, service.ErrUserExists)) {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	} else if err != nil {
+		h.logger.Info("LoginExternalUser service", zap.Error(err))
+		http.Error(w, fmt.Sprintf("service error: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	h.writeAuthResponse(w, account, session, http.StatusOK)
+}
+
+func (h *Handlers) setOIDCFlowCookie(w http.ResponseWriter, flow oidcFlowClaims) error {
+	key := h.tokens.keyring.Current()
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, flow)
+	token.Header["kid"] = key.ID
+	value, err := token.SignedString(key.Secret)
+	if err != nil {
+		return fmt.Errorf("failed signing state: %w", err)
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidc_state_cookie,
+		Value:    value,
+		Path:     OIDCCallbackPath,
+		Expires:  time.Now().Add(oidc_state_exp),
+		HttpOnly: true,
+		Secure:   h.tokens.auth.CookieSecure,
+		// cookie must be sent on top level redirect from identity provider
+		SameSite: http.SameSiteLaxMode,
+	})
+	return nil
+}
+
+func (h *Handlers) parseOIDCFlowCookie(r *http.Request) (*oidcFlowClaims, error) {
+	cookie, err := r.Cookie(oidc_state_cookie)
+	if err != nil {
+		return nil, fmt.Errorf("failed get state cookie: %w", err)
+	}
+	flow := &oidcFlowClaims{}
+	_, err = jwt.ParseWithClaims(cookie.Value, flow,
+		func(t *jwt.Token) (interface{}, error) {
+			kid, _ := t.Header["kid"].(string)
+			key, _, err := h.tokens.keyring.Lookup(kid)
+			if err != nil {
+				return nil, err
+			}
+			return key.Secret, nil
+		},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed parse state: %w", err)
+	}
+	return flow, nil
+}
+
+func (h *Handlers) clearOIDCFlowCookie(w http.ResponseWriter) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidc_state_cookie,
+		Value:    "",
+		Path:     OIDCCallbackPath,
+		Expires:  time.Unix(0, 0),
+		MaxAge:   -1,
+		HttpOnly: true,
+	})
+}
@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/hollgett/shortener.git/internal/service"
+	"go.uber.org/zap"
+)
+
+const operationsPath = "/api/user/operations/"
+
+// GetAPIUserOperation return status of delete request "/api/user/operations/{id}" with result of every link.
+func (h *Handlers) GetAPIUserOperation(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	user, err := parseAuthorizedUser(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed parse userID: %s", err.Error()), http.StatusUnauthorized)
+		return
+	}
+	if !user.HasScope(service.ScopeLinksDelete) {
+		http.Error(w, "api key scope links:delete required", http.StatusForbidden)
+		return
+	}
+
+	operationID := strings.TrimPrefix(r.URL.Path, operationsPath)
+	if len(operationID) == 0 || strings.Contains(operationID, "/") {
+		http.NotFound(w, r)
+		return
+	}
+	operation, err := h.service.GetUserOperation(user.ID, operationID)
+	if err != nil && errors.Is(err, service.ErrOperationNotExists) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	} else if err != nil {
+		h.logger.Info("GetAPIUserOperation service", zap.Error(err))
+		http.Error(w, fmt.Sprintf("failed get operation: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+	h.writeJSON(w, operation, http.StatusOK)
+}
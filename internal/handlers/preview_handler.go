@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"html/template"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/hollgett/shortener.git/internal/models"
+	"go.uber.org/zap"
+)
+
+// suffix of short link which opens preview page instead of redirect
+const previewSuffix = "+"
+
+var previewTemplate = template.Must(template.New("preview").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="robots" content="noindex">
+<title>{{if .Title}}{{.Title}}{{else}}Link preview{{end}}</title>
+</head>
+<body>
+<main>
+<h1>{{if .Title}}{{.Title}}{{else}}Link preview{{end}}</h1>
+<p>{{.ShortURL}} leads to <strong>{{.Host}}</strong></p>
+<p><code>{{.OriginalURL}}</code></p>
+{{if .CreatedAt}}<p>Created {{.CreatedAt}}</p>{{end}}
+<p><a href="{{.OriginalURL}}" rel="noopener noreferrer">Continue</a></p>
+</main>
+</body>
+</html>
+`))
+
+type previewPage struct {
+	ShortURL    string
+	OriginalURL string
+	Host        string
+	Title       string
+	CreatedAt   string
+}
+
+// render page with destination of link instead of redirect
+func (h *Handlers) writePreview(w http.ResponseWriter, short string, redirect models.Redirect) {
+	page := previewPage{
+		ShortURL:    h.baseURL + "/" + short,
+		OriginalURL: redirect.OriginalURL,
+		Title:       redirect.Title,
+	}
+	if parsed, err := url.Parse(redirect.OriginalURL); err == nil {
+		page.Host = parsed.Host
+	}
+	if redirect.CreatedAt != nil {
+		page.CreatedAt = redirect.CreatedAt.UTC().Format(time.DateOnly)
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	// page must show current destination of link
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	if err := previewTemplate.Execute(w, page); err != nil {
+		h.logger.Info("render preview", zap.Error(err))
+	}
+}
+
+// link leads outside of service domain and its subdomains
+func (h *Handlers) isExternal(originalURL string) bool {
+	base, err := url.Parse(h.baseURL)
+	if err != nil {
+		return true
+	}
+	target, err := url.Parse(originalURL)
+	if err != nil {
+		return true
+	}
+	baseHost, targetHost := strings.ToLower(base.Hostname()), strings.ToLower(target.Hostname())
+	return targetHost != baseHost && !strings.HasSuffix(targetHost, "."+baseHost)
+}
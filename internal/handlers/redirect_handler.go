@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/hollgett/shortener.git/internal/models"
+	"github.com/hollgett/shortener.git/internal/service"
+	"go.uber.org/zap"
+)
+
+const userURLsPath = "/api/user/urls/"
+
+// UpdateAPIUserURL change redirect policy and title of link, "/api/user/urls/{short}" PATCH.
+func (h *Handlers) UpdateAPIUserURL(w http.ResponseWriter, r *http.Request) {
+	shortURL := strings.TrimPrefix(r.URL.Path, userURLsPath)
+	if len(shortURL) == 0 || strings.Contains(shortURL, "/") {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodPatch {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	user, err := parseAuthorizedUser(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed parse userID: %s", err.Error()), http.StatusUnauthorized)
+		return
+	}
+	if !user.HasScope(service.ScopeLinksWrite) {
+		http.Error(w, "api key scope links:write required", http.StatusForbidden)
+		return
+	}
+
+	var req models.UserURLUpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Info("UpdateAPIUserURL decode", zap.Error(err))
+		http.Error(w, fmt.Sprintf("failed decode body: %s", err.Error()), http.StatusBadRequest)
+		return
+	}
+
+	resp, err := h.service.UpdateUserURL(newActor(r, user), shortURL, req)
+	switch {
+	case err == nil:
+		h.writeJSON(w, resp, http.StatusOK)
+	case errors.Is(err, service.ErrInvalidRedirect), errors.Is(err, service.ErrInvalidTitle):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, service.ErrURLDeleted):
+		http.Error(w, err.Error(), http.StatusGone)
+	default:
+		h.writeWorkspaceError(w, "UpdateUserURL", err)
+	}
+}
@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+)
+
+const (
+	request_id_header = "X-Request-ID"
+	len_request_id    = 16
+	// longer request id from client is replaced
+	max_len_request_id = 64
+
+	RequestIDKeyCtx ctxKey = "RequestID"
+)
+
+// RequestID take request id from client or generate it, put it to context and response header.
+func (m *Middleware) RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get(request_id_header)
+		if len(requestID) == 0 || len(requestID) > max_len_request_id {
+			requestID = generateRequestID()
+		}
+		w.Header().Set(request_id_header, requestID)
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), RequestIDKeyCtx, requestID)))
+	})
+}
+
+func generateRequestID() string {
+	b := make([]byte, len_request_id)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/hollgett/shortener.git/internal/models"
 	"github.com/hollgett/shortener.git/internal/service"
 	"go.uber.org/zap"
 )
@@ -34,11 +35,15 @@ func (h *Handlers) CreateOrRedirectText(w http.ResponseWriter, r *http.Request)
 // create short url return response text plain
 func (h *Handlers) createShortURLHandler(w http.ResponseWriter, r *http.Request) {
 	//get user id
-	user, err := parseUserID(r)
+	user, err := h.parseCreatingUser(w, r)
 	if err != nil {
 		http.Error(w, fmt.Sprintf("parse user id error: %s", err.Error()), http.StatusInternalServerError)
 		return
 	}
+	if !user.HasScope(service.ScopeLinksWrite) {
+		http.Error(w, "api key scope links:write required", http.StatusForbidden)
+		return
+	}
 
 	//read request body
 	originalURL, err := io.ReadAll(r.Body)
@@ -50,7 +55,7 @@ func (h *Handlers) createShortURLHandler(w http.ResponseWriter, r *http.Request)
 
 	//service logic
 	var statusCode int
-	shortLink, err := h.service.CreateShortURL(user.ID, string(originalURL))
+	shortLink, err := h.service.CreateShortURL(r.Context(), newActor(r, user), string(originalURL), "", models.RedirectPolicy{})
 	if err != nil && errors.Is(err, service.ErrShortExists) {
 		statusCode = http.StatusConflict
 	} else if err != nil {
@@ -66,23 +71,47 @@ func (h *Handlers) createShortURLHandler(w http.ResponseWriter, r *http.Request)
 
 }
 
-// redirect short url to original link
+// redirect short url to original link, "/{short}+" or "?preview=1" render preview page instead
 func (h *Handlers) redirectShortURL(w http.ResponseWriter, r *http.Request) {
 	reqShort := strings.Trim(r.URL.Path, "/")
+	preview := r.URL.Query().Get("preview") == "1"
+	if short, ok := strings.CutSuffix(reqShort, previewSuffix); ok {
+		reqShort, preview = short, true
+	}
 
-	originalURL, err := h.service.GetOriginalURLService(reqShort)
-	if err != nil && errors.Is(err, service.ErrURLDeleted) {
-		h.logger.Info("GetOriginalURLService", zap.Error(err))
-		http.Error(w, fmt.Sprintf("GetOriginalURLService error: %s", err.Error()), http.StatusGone)
+	redirect, err := h.service.GetRedirect(r.Context(), reqShort)
+	if err != nil && (errors.Is(err, service.ErrURLDeleted) || errors.Is(err, service.ErrURLDisabled)) {
+		h.logger.Info("GetRedirect", zap.Error(err))
+		http.Error(w, fmt.Sprintf("GetRedirect error: %s", err.Error()), http.StatusGone)
 		return
 	} else if err != nil {
-		h.logger.Info("GetOriginalURLService", zap.Error(err))
-		http.Error(w, fmt.Sprintf("GetOriginalURLService error: %s", err.Error()), http.StatusBadRequest)
+		h.logger.Info("GetRedirect", zap.Error(err))
+		http.Error(w, fmt.Sprintf("GetRedirect error: %s", err.Error()), http.StatusBadRequest)
+		return
+	}
+
+	if preview || (h.redirect.PreviewExternal && h.isExternal(redirect.OriginalURL)) {
+		h.writePreview(w, reqShort, redirect)
 		return
 	}
 
-	w.Header().Add("Location", originalURL)
-	w.WriteHeader(http.StatusTemporaryRedirect)
+	status := redirect.RedirectStatus
+	if status == 0 {
+		status = h.redirect.Status
+	}
+	cacheControl := redirect.CacheControl
+	if len(cacheControl) == 0 {
+		cacheControl = h.redirect.CacheControl
+	}
+	// cached redirect never reaches service, tracked link must be counted on every visit
+	if redirect.Tracked {
+		cacheControl = "private, no-store"
+	}
+	if len(cacheControl) != 0 {
+		w.Header().Set("Cache-Control", cacheControl)
+	}
+	w.Header().Add("Location", redirect.OriginalURL)
+	w.WriteHeader(status)
 }
 
 func (h *Handlers) PingDatabase(w http.ResponseWriter, r *http.Request) {
@@ -92,3 +121,33 @@ func (h *Handlers) PingDatabase(w http.ResponseWriter, r *http.Request) {
 	}
 	w.WriteHeader(http.StatusOK)
 }
+
+// GetInternalStats return statistics of service, must be wrapped by TrustedSubnet
+func (h *Handlers) GetInternalStats(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	stats, err := h.service.GetStats()
+	if err != nil {
+		h.logger.Info("GetStats service", zap.Error(err))
+		http.Error(w, fmt.Sprintf("service error: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+	h.writeJSON(w, stats, http.StatusOK)
+}
+
+// GetInternalLeader return leader of scheduled jobs, must be wrapped by TrustedSubnet
+func (h *Handlers) GetInternalLeader(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	status, err := h.service.GetLeaderStatus()
+	if err != nil {
+		h.logger.Info("GetLeaderStatus service", zap.Error(err))
+		http.Error(w, fmt.Sprintf("service error: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+	h.writeJSON(w, status, http.StatusOK)
+}
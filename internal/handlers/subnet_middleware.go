@@ -0,0 +1,26 @@
+package handlers
+
+import (
+	"net"
+	"net/http"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+const real_ip_header = "X-Real-IP"
+
+// TrustedSubnet allow requests only from clients with X-Real-IP in subnet, nil subnet forbid all requests.
+func (m *Middleware) TrustedSubnet(subnet *net.IPNet) middlewareConv {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ip := net.ParseIP(strings.TrimSpace(r.Header.Get(real_ip_header)))
+			if subnet == nil || ip == nil || !subnet.Contains(ip) {
+				m.logger.Info("untrusted client", zap.String("real ip", r.Header.Get(real_ip_header)))
+				http.Error(w, "client ip is not in trusted subnet", http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,181 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+type Claims struct {
+	jwt.RegisteredClaims
+	UserID string
+	// session of registered user, empty for anonymous user
+	SessionID string `json:"sid,omitempty"`
+}
+
+// AuthOptions attributes of auth cookie and token lifetime
+type AuthOptions struct {
+	CookieDomain   string
+	CookieSecure   bool
+	CookieSameSite http.SameSite
+	// lifetime of token and cookie
+	TokenTTL time.Duration
+	// token is re-issued when it expires earlier than this period
+	RefreshBefore time.Duration
+}
+
+// TokenIssuer sign, verify auth tokens and set them to cookie
+type TokenIssuer struct {
+	keyring *Keyring
+	auth    AuthOptions
+}
+
+// build token issuer with keyring and cookie attributes
+func NewTokenIssuer(keyring *Keyring, auth AuthOptions) *TokenIssuer {
+	return &TokenIssuer{
+		keyring: keyring,
+		auth:    auth,
+	}
+}
+
+// NeedRefresh return true if token signed with previous key or near expiry
+func (t *TokenIssuer) NeedRefresh(claims *Claims, current bool) bool {
+	if !current {
+		return true
+	}
+	if claims.ExpiresAt == nil {
+		return true
+	}
+	return time.Until(claims.ExpiresAt.Time) < t.refreshBefore()
+}
+
+func (t *TokenIssuer) tokenTTL() time.Duration {
+	if t.auth.TokenTTL <= 0 {
+		return token_exp
+	}
+	return t.auth.TokenTTL
+}
+
+func (t *TokenIssuer) refreshBefore() time.Duration {
+	if t.auth.RefreshBefore <= 0 {
+		return token_refresh
+	}
+	return t.auth.RefreshBefore
+}
+
+// Build sign token with current key, sessionID is empty for anonymous user
+func (t *TokenIssuer) Build(userID, sessionID string) (string, error) {
+	key := t.keyring.Current()
+	tokenJWT := jwt.NewWithClaims(jwt.SigningMethodHS256, Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(t.tokenTTL())),
+		},
+		UserID:    userID,
+		SessionID: sessionID,
+	})
+	tokenJWT.Header["kid"] = key.ID
+
+	token, err := tokenJWT.SignedString(key.Secret)
+	if err != nil {
+		return "", fmt.Errorf("failed signing token: %w", err)
+	}
+	return token, nil
+}
+
+// Parse verify token by key from "kid" header, current is false if token signed with previous key.
+//
+// tokens without "kid" verified by all keys in keyring.
+func (t *TokenIssuer) Parse(tokenStr string) (claims *Claims, current bool, err error) {
+	claims = &Claims{}
+
+	token, err := jwt.ParseWithClaims(tokenStr, claims,
+		func(token *jwt.Token) (interface{}, error) {
+			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+			}
+			kid, ok := token.Header["kid"].(string)
+			if !ok {
+				return t.verificationKeys(), nil
+			}
+			key, isCurrent, err := t.keyring.Lookup(kid)
+			if err != nil {
+				return nil, fmt.Errorf("kid %q: %w", kid, err)
+			}
+			current = isCurrent
+			return key.Secret, nil
+		})
+	if err != nil {
+		return nil, false, fmt.Errorf("failed parse token: %w", err)
+	}
+
+	if !token.Valid || len(claims.UserID) == 0 {
+		return nil, false, errors.New("token is not valid")
+	}
+
+	return claims, current, nil
+}
+
+// all keys of keyring for verification of legacy tokens without "kid"
+func (t *TokenIssuer) verificationKeys() jwt.VerificationKeySet {
+	keys := t.keyring.Keys()
+	set := jwt.VerificationKeySet{Keys: make([]jwt.VerificationKey, len(keys))}
+	for i, key := range keys {
+		set.Keys[i] = key.Secret
+	}
+	return set
+}
+
+// SetCookie build token and set it to auth cookie, return token
+func (t *TokenIssuer) SetCookie(w http.ResponseWriter, userID, sessionID string) (string, error) {
+	token, err := t.Build(userID, sessionID)
+	if err != nil {
+		return "", fmt.Errorf("failed build jwt: %w", err)
+	}
+	NewCookie := &http.Cookie{
+		Name:     token_user_cookie,
+		Value:    token,
+		Path:     "/",
+		Domain:   t.auth.CookieDomain,
+		Expires:  time.Now().Add(t.tokenTTL()),
+		MaxAge:   int(t.tokenTTL().Seconds()),
+		HttpOnly: true,
+		Secure:   t.auth.CookieSecure,
+		SameSite: t.auth.CookieSameSite,
+	}
+	http.SetCookie(w, NewCookie)
+	return token, nil
+}
+
+// ClearCookie remove auth cookie from client
+func (t *TokenIssuer) ClearCookie(w http.ResponseWriter) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     token_user_cookie,
+		Value:    "",
+		Path:     "/",
+		Domain:   t.auth.CookieDomain,
+		Expires:  time.Unix(0, 0),
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   t.auth.CookieSecure,
+		SameSite: t.auth.CookieSameSite,
+	})
+}
+
+// ParseSameSite convert config value "lax", "strict", "none" or empty to cookie SameSite mode
+func ParseSameSite(mode string) (http.SameSite, error) {
+	switch strings.ToLower(strings.TrimSpace(mode)) {
+	case "":
+		return http.SameSiteDefaultMode, nil
+	case "lax":
+		return http.SameSiteLaxMode, nil
+	case "strict":
+		return http.SameSiteStrictMode, nil
+	case "none":
+		return http.SameSiteNoneMode, nil
+	}
+	return 0, fmt.Errorf("unknown same site mode %q", mode)
+}
@@ -0,0 +1,440 @@
+package handlers
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hollgett/shortener.git/internal/models"
+	"github.com/hollgett/shortener.git/internal/service"
+	"go.uber.org/zap"
+)
+
+// formats of export and import files
+const (
+	formatCSV    = "csv"
+	formatJSON   = "json"
+	formatNDJSON = "ndjson"
+)
+
+// max size of import body
+const maxImportBodySize = 10 << 20
+
+var csvExportHeader = []string{"short_url", "original_url", "workspace_id", "is_deleted", "deleted_at", "is_disabled",
+	"title", "created_at", "redirect_status", "cache_control", "tracked"}
+
+// ExportAPIUserURLs stream all links of user including deleted ones as csv, json or ndjson file.
+func (h *Handlers) ExportAPIUserURLs(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	user, err := parseAuthorizedUser(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed parse userID: %s", err.Error()), http.StatusUnauthorized)
+		return
+	}
+	if !user.HasScope(service.ScopeLinksRead) {
+		http.Error(w, "api key scope links:read required", http.StatusForbidden)
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = formatJSON
+	}
+	exporter, contentType, err := newExporter(format, w)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// response is started with first page, so error of first read is still returned as status
+	started := false
+	start := func() error {
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="urls.%s"`, format))
+		w.WriteHeader(http.StatusOK)
+		started = true
+		return exporter.begin()
+	}
+	err = h.service.ExportUserURLs(user.ID, func(URLs []models.ShortenerURL) error {
+		if !started {
+			if err := start(); err != nil {
+				return err
+			}
+		}
+		for _, URL := range URLs {
+			if err := exporter.write(h.exportedURL(URL)); err != nil {
+				return err
+			}
+		}
+		if err := exporter.flush(); err != nil {
+			return err
+		}
+		http.NewResponseController(w).Flush()
+		return nil
+	})
+	if err == nil && !started {
+		err = start()
+	}
+	if err == nil {
+		err = exporter.end()
+	}
+	if err != nil {
+		h.logger.Info("ExportAPIUserURLs", zap.Error(err))
+		if !started {
+			http.Error(w, fmt.Sprintf("failed export URLs: %s", err.Error()), http.StatusInternalServerError)
+		}
+	}
+}
+
+// ImportAPIUserURLs create links from csv, json or ndjson file sent as raw body or multipart field "file", return result of every row.
+//
+// format is taken from query parameter "format", else from content type or extension of file
+func (h *Handlers) ImportAPIUserURLs(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	user, err := parseAuthorizedUser(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed parse userID: %s", err.Error()), http.StatusUnauthorized)
+		return
+	}
+	if !user.HasScope(service.ScopeLinksWrite) {
+		http.Error(w, "api key scope links:write required", http.StatusForbidden)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportBodySize)
+	format := r.URL.Query().Get("format")
+	var body io.Reader = r.Body
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == "multipart/form-data" {
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			http.Error(w, fmt.Sprintf("failed read file: %s", err.Error()), http.StatusBadRequest)
+			return
+		}
+		defer file.Close()
+		body = file
+		if format == "" {
+			format = strings.TrimPrefix(path.Ext(header.Filename), ".")
+		}
+		mediaType, _, _ = mime.ParseMediaType(header.Header.Get("Content-Type"))
+	}
+	format = importFormat(format, mediaType)
+
+	rows, err := parseImport(format, body)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed parse import: %s", err.Error()), http.StatusBadRequest)
+		return
+	}
+	report, err := h.service.ImportUserURLs(r.Context(), newActor(r, user), rows)
+	if errors.Is(err, service.ErrImportTooLarge) {
+		http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+		return
+	} else if err != nil {
+		h.logger.Info("ImportAPIUserURLs service", zap.Error(err))
+		http.Error(w, fmt.Sprintf("failed import URLs: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+	for i, result := range report.Rows {
+		if result.ShortURL != "" {
+			report.Rows[i].ShortURL = fmt.Sprintf("%s/%s", h.baseURL, result.ShortURL)
+		}
+	}
+	h.writeJSON(w, report, http.StatusOK)
+}
+
+func (h *Handlers) exportedURL(URL models.ShortenerURL) models.ExportedURL {
+	return models.ExportedURL{
+		ShortURL:       fmt.Sprintf("%s/%s", h.baseURL, URL.ShortURL),
+		OriginalURL:    URL.OriginalURL,
+		WorkspaceID:    URL.WorkspaceID,
+		Deleted:        URL.DeletedFlag,
+		DeletedAt:      URL.DeletedAt,
+		Disabled:       URL.DisabledFlag,
+		Title:          URL.Title,
+		CreatedAt:      URL.CreatedAt,
+		RedirectPolicy: URL.RedirectPolicy,
+	}
+}
+
+// format of import set explicitly or by file extension, else by media type, jsonl is alias of ndjson
+func importFormat(format, mediaType string) string {
+	switch format {
+	case formatCSV, formatJSON, formatNDJSON:
+		return format
+	case "jsonl":
+		return formatNDJSON
+	}
+	switch mediaType {
+	case "text/csv":
+		return formatCSV
+	case "application/json":
+		return formatJSON
+	case "application/x-ndjson", "application/jsonl":
+		return formatNDJSON
+	}
+	return format
+}
+
+// writer of export file, begin and end are called once around written links
+type exporter interface {
+	begin() error
+	write(URL models.ExportedURL) error
+	// write buffered links to response
+	flush() error
+	end() error
+}
+
+func newExporter(format string, w io.Writer) (exporter, string, error) {
+	switch format {
+	case formatCSV:
+		return &csvExporter{w: csv.NewWriter(w)}, "text/csv", nil
+	case formatJSON:
+		return &jsonExporter{w: w}, "application/json", nil
+	case formatNDJSON:
+		return &ndjsonExporter{enc: json.NewEncoder(w)}, "application/x-ndjson", nil
+	}
+	return nil, "", fmt.Errorf("unknown format %q, expected csv, json or ndjson", format)
+}
+
+type csvExporter struct {
+	w *csv.Writer
+}
+
+func (e *csvExporter) begin() error {
+	return e.w.Write(csvExportHeader)
+}
+
+func (e *csvExporter) write(URL models.ExportedURL) error {
+	redirectStatus := ""
+	if URL.RedirectStatus != 0 {
+		redirectStatus = strconv.Itoa(URL.RedirectStatus)
+	}
+	return e.w.Write([]string{URL.ShortURL, URL.OriginalURL, URL.WorkspaceID,
+		strconv.FormatBool(URL.Deleted), formatCSVTime(URL.DeletedAt), strconv.FormatBool(URL.Disabled),
+		URL.Title, formatCSVTime(URL.CreatedAt), redirectStatus, URL.CacheControl, strconv.FormatBool(URL.Tracked)})
+}
+
+func formatCSVTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+func (e *csvExporter) flush() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+func (e *csvExporter) end() error {
+	return e.flush()
+}
+
+// json array written by elements
+type jsonExporter struct {
+	w       io.Writer
+	written bool
+}
+
+func (e *jsonExporter) begin() error {
+	_, err := io.WriteString(e.w, "[")
+	return err
+}
+
+func (e *jsonExporter) write(URL models.ExportedURL) error {
+	data, err := json.Marshal(URL)
+	if err != nil {
+		return err
+	}
+	if e.written {
+		if _, err := io.WriteString(e.w, ","); err != nil {
+			return err
+		}
+	}
+	e.written = true
+	_, err = e.w.Write(data)
+	return err
+}
+
+func (e *jsonExporter) flush() error {
+	return nil
+}
+
+func (e *jsonExporter) end() error {
+	_, err := io.WriteString(e.w, "]\n")
+	return err
+}
+
+type ndjsonExporter struct {
+	enc *json.Encoder
+}
+
+func (e *ndjsonExporter) begin() error {
+	return nil
+}
+
+func (e *ndjsonExporter) write(URL models.ExportedURL) error {
+	return e.enc.Encode(URL)
+}
+
+func (e *ndjsonExporter) flush() error {
+	return nil
+}
+
+func (e *ndjsonExporter) end() error {
+	return nil
+}
+
+// parse rows of import file, broken rows are returned with error and reported as invalid
+func parseImport(format string, body io.Reader) ([]models.ImportRow, error) {
+	switch format {
+	case formatCSV:
+		return parseImportCSV(body)
+	case formatJSON:
+		var URLs []models.ExportedURL
+		if err := json.NewDecoder(body).Decode(&URLs); err != nil {
+			return nil, fmt.Errorf("failed decode json: %w", err)
+		}
+		rows := make([]models.ImportRow, len(URLs))
+		for i, URL := range URLs {
+			rows[i] = importRow(i+1, URL)
+		}
+		return rows, nil
+	case formatNDJSON:
+		return parseImportNDJSON(body)
+	}
+	return nil, fmt.Errorf("unknown format %q, expected csv, json or ndjson", format)
+}
+
+// row of exported link from json file
+func importRow(n int, URL models.ExportedURL) models.ImportRow {
+	return models.ImportRow{
+		Row:            n,
+		OriginalURL:    URL.OriginalURL,
+		Deleted:        URL.Deleted,
+		Title:          URL.Title,
+		CreatedAt:      URL.CreatedAt,
+		RedirectPolicy: URL.RedirectPolicy,
+	}
+}
+
+// csv must have header with original_url column, other columns of export are optional
+func parseImportCSV(body io.Reader) ([]models.ImportRow, error) {
+	reader := csv.NewReader(body)
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed read csv header: %w", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.TrimSpace(name)] = i
+	}
+	originalCol, ok := columns["original_url"]
+	if !ok {
+		return nil, errors.New("csv header must have original_url column")
+	}
+
+	rows := make([]models.ImportRow, 0)
+	for n := 1; ; n++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return rows, nil
+		}
+		row := models.ImportRow{Row: n}
+		var parseErr *csv.ParseError
+		switch {
+		case errors.As(err, &parseErr):
+			row.Error = parseErr.Err.Error()
+		case err != nil:
+			return nil, fmt.Errorf("failed read csv: %w", err)
+		case originalCol >= len(record):
+			row.Error = "original_url column is missing"
+		default:
+			row.OriginalURL = strings.TrimSpace(record[originalCol])
+			if err := parseImportCSVColumns(&row, columns, record); err != nil {
+				row.Error = err.Error()
+			}
+		}
+		rows = append(rows, row)
+	}
+}
+
+// fill optional columns of row, empty or missing column keeps default value
+func parseImportCSVColumns(row *models.ImportRow, columns map[string]int, record []string) error {
+	value := func(name string) string {
+		if i, ok := columns[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+	var err error
+	if v := value("is_deleted"); v != "" {
+		if row.Deleted, err = strconv.ParseBool(v); err != nil {
+			return errors.New("invalid is_deleted")
+		}
+	}
+	if v := value("tracked"); v != "" {
+		if row.Tracked, err = strconv.ParseBool(v); err != nil {
+			return errors.New("invalid tracked")
+		}
+	}
+	if v := value("redirect_status"); v != "" {
+		if row.RedirectStatus, err = strconv.Atoi(v); err != nil {
+			return errors.New("invalid redirect_status")
+		}
+	}
+	if v := value("created_at"); v != "" {
+		createdAt, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return errors.New("invalid created_at")
+		}
+		createdAt = createdAt.UTC()
+		row.CreatedAt = &createdAt
+	}
+	row.Title = value("title")
+	row.CacheControl = value("cache_control")
+	return nil
+}
+
+// every non empty line is json object
+func parseImportNDJSON(body io.Reader) ([]models.ImportRow, error) {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxImportBodySize)
+	rows := make([]models.ImportRow, 0)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		row := models.ImportRow{Row: len(rows) + 1}
+		var URL models.ExportedURL
+		if err := json.Unmarshal([]byte(line), &URL); err != nil {
+			row.Error = fmt.Sprintf("failed decode line: %s", err.Error())
+		} else {
+			row = importRow(row.Row, URL)
+		}
+		rows = append(rows, row)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed read ndjson: %w", err)
+	}
+	return rows, nil
+}
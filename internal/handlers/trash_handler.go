@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/hollgett/shortener.git/internal/service"
+	"go.uber.org/zap"
+)
+
+// GetAPIUserTrash return deleted links of user which are not purged yet.
+func (h *Handlers) GetAPIUserTrash(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	user, err := parseAuthorizedUser(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed parse userID: %s", err.Error()), http.StatusUnauthorized)
+		return
+	}
+	if !user.HasScope(service.ScopeLinksRead) {
+		http.Error(w, "api key scope links:read required", http.StatusForbidden)
+		return
+	}
+
+	URLs, err := h.service.GetUserTrash(user.ID)
+	if err != nil {
+		h.logger.Info("GetAPIUserTrash service", zap.Error(err))
+		http.Error(w, fmt.Sprintf("failed get deleted URLs: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+	if len(URLs) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	for i, URL := range URLs {
+		URLs[i].ShortURL = fmt.Sprintf("%s/%s", h.baseURL, URL.ShortURL)
+	}
+	h.writeJSON(w, URLs, http.StatusOK)
+}
+
+// RestoreAPIUserURLs undelete links of user by list of short ids, return restored ids.
+func (h *Handlers) RestoreAPIUserURLs(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	user, err := parseAuthorizedUser(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed parse userID: %s", err.Error()), http.StatusUnauthorized)
+		return
+	}
+	if !user.HasScope(service.ScopeLinksDelete) {
+		http.Error(w, "api key scope links:delete required", http.StatusForbidden)
+		return
+	}
+
+	var shortURLs []string
+	if err := json.NewDecoder(r.Body).Decode(&shortURLs); err != nil {
+		http.Error(w, fmt.Sprintf("failed decode body: %s", err.Error()), http.StatusBadRequest)
+		return
+	}
+	restored, err := h.service.RestoreUserURLs(newActor(r, user), shortURLs)
+	if err != nil {
+		h.logger.Info("RestoreAPIUserURLs service", zap.Error(err))
+		http.Error(w, fmt.Sprintf("failed restore URLs: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+	h.writeJSON(w, restored, http.StatusOK)
+}
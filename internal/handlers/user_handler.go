@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/hollgett/shortener.git/internal/models"
+	"github.com/hollgett/shortener.git/internal/service"
+	"go.uber.org/zap"
+)
+
+// RegisterUser create account with login and password for current user, URLs of current user stay with account.
+func (h *Handlers) RegisterUser(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	user, err := h.parseCreatingUser(w, r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("parse user id error: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+	if len(user.APIKeyID) != 0 {
+		http.Error(w, "not allowed with api key", http.StatusForbidden)
+		return
+	}
+
+	var req models.AuthRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Info("RegisterUser decode", zap.Error(err))
+		http.Error(w, fmt.Sprintf("failed decode body: %s", err.Error()), http.StatusBadRequest)
+		return
+	}
+
+	account, session, err := h.service.RegisterUser(user.ID, req.Login, req.Password)
+	switch {
+	case errors.Is(err, service.ErrInvalidAuthData):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case errors.Is(err, service.ErrUserExists), errors.Is(err, service.ErrAlreadyRegistered):
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	case err != nil:
+		h.logger.Info("RegisterUser service", zap.Error(err))
+		http.Error(w, fmt.Sprintf("service error: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	h.writeAuthResponse(w, account, session, http.StatusCreated)
+}
+
+// LoginUser check login and password, URLs of current anonymous user are merged to account.
+func (h *Handlers) LoginUser(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	user, err := parseUserID(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("parse user id error: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+	if len(user.APIKeyID) != 0 {
+		http.Error(w, "not allowed with api key", http.StatusForbidden)
+		return
+	}
+	// user created by middleware has no URLs to claim
+	anonymousID := user.ID
+	if user.Err != nil || len(user.SessionID) != 0 {
+		anonymousID = ""
+	}
+
+	var req models.AuthRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Info("LoginUser decode", zap.Error(err))
+		http.Error(w, fmt.Sprintf("failed decode body: %s", err.Error()), http.StatusBadRequest)
+		return
+	}
+
+	account, session, err := h.service.LoginUser(anonymousID, req.Login, req.Password)
+	if err != nil && errors.Is(err, service.ErrInvalidCredentials) {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	} else if err != nil {
+		h.logger.Info("LoginUser service", zap.Error(err))
+		http.Error(w, fmt.Sprintf("service error: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	h.writeAuthResponse(w, account, session, http.StatusOK)
+}
+
+// LogoutUser revoke current session and remove cookie, with "?all=true" revoke all sessions of user.
+func (h *Handlers) LogoutUser(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	user, err := parseAuthorizedUser(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed parse userID: %s", err.Error()), http.StatusUnauthorized)
+		return
+	}
+	if len(user.APIKeyID) != 0 {
+		http.Error(w, "not allowed with api key", http.StatusForbidden)
+		return
+	}
+
+	if len(user.SessionID) != 0 {
+		all := r.URL.Query().Get("all") == "true"
+		if err := h.service.LogoutUser(user.ID, user.SessionID, all); err != nil {
+			h.logger.Info("LogoutUser service", zap.Error(err))
+			http.Error(w, fmt.Sprintf("service error: %s", err.Error()), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	h.tokens.ClearCookie(w)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// set auth cookie with session and write token to response
+func (h *Handlers) writeAuthResponse(w http.ResponseWriter, user models.User, session models.Session, statusCode int) {
+	token, err := h.tokens.SetCookie(w, user.ID, session.ID)
+	if err != nil {
+		h.logger.Info("set auth cookie", zap.Error(err))
+		http.Error(w, fmt.Sprintf("failed set cookie: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	resp, err := json.Marshal(models.AuthResponse{
+		UserID: user.ID,
+		Login:  user.Login,
+		Token:  token,
+	})
+	if err != nil {
+		h.logger.Info("marshal auth response", zap.Error(err))
+		http.Error(w, fmt.Sprintf("failed marshal response: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Add("Content-Type", "application/json")
+	w.Header().Set("Authorization", authorization_token+token)
+	w.WriteHeader(statusCode)
+	w.Write(resp)
+}
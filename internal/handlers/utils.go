@@ -1,8 +1,16 @@
 package handlers
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
+	"net"
 	"net/http"
+	"strings"
+
+	"github.com/hollgett/shortener.git/internal/models"
+
+	"go.uber.org/zap"
 )
 
 func parseUserID(r *http.Request) (User, error) {
@@ -15,3 +23,65 @@ func parseUserID(r *http.Request) (User, error) {
 
 	return val, nil
 }
+
+// parseAuthorizedUser return user only if request had valid token, user created by middleware is rejected
+func parseAuthorizedUser(r *http.Request) (User, error) {
+	user, err := parseUserID(r)
+	if err != nil {
+		return user, err
+	}
+	if user.Err != nil {
+		return user, user.Err
+	}
+	return user, nil
+}
+
+// parseCreatingUser return user of request for paths which create anonymous user, request with invalid token
+// gets new user and cookie, error of token is kept in user
+func (h *Handlers) parseCreatingUser(w http.ResponseWriter, r *http.Request) (User, error) {
+	user, err := parseUserID(r)
+	if err != nil || len(user.ID) != 0 {
+		return user, err
+	}
+	userID := generateUserID()
+	if _, err := h.tokens.SetCookie(w, userID, ""); err != nil {
+		return user, fmt.Errorf("failed set user cookie: %w", err)
+	}
+	user.ID = userID
+	return user, nil
+}
+
+// build actor of request for audit log
+func newActor(r *http.Request, user User) models.Actor {
+	requestID, _ := r.Context().Value(RequestIDKeyCtx).(string)
+	return models.Actor{
+		UserID:    user.ID,
+		RequestID: requestID,
+		IP:        clientIP(r),
+	}
+}
+
+// ip of client from X-Real-IP set by proxy or from remote address, invalid header is ignored
+func clientIP(r *http.Request) string {
+	if ip := net.ParseIP(strings.TrimSpace(r.Header.Get(real_ip_header))); ip != nil {
+		return ip.String()
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// marshal data to json and write response with status code
+func (h *Handlers) writeJSON(w http.ResponseWriter, data any, statusCode int) {
+	resp, err := json.Marshal(data)
+	if err != nil {
+		h.logger.Info("marshal response", zap.Error(err))
+		http.Error(w, fmt.Sprintf("failed marshal response: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Add("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	w.Write(resp)
+}
@@ -0,0 +1,238 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/hollgett/shortener.git/internal/models"
+	"github.com/hollgett/shortener.git/internal/service"
+	"go.uber.org/zap"
+)
+
+const workspacesPath = "/api/workspaces"
+
+// ControllerWorkspaces call function dependent on request method and path.
+//
+// "/api/workspaces" POST create, GET list own workspaces;
+// "/api/workspaces/{id}/members" GET list, POST add or change role; "/api/workspaces/{id}/members/{user}" DELETE remove;
+// "/api/workspaces/{id}/urls" GET list, POST create or move own link, DELETE delete; "/api/workspaces/{id}/urls/{short}" PUT update.
+func (h *Handlers) ControllerWorkspaces(w http.ResponseWriter, r *http.Request) {
+	user, err := parseAuthorizedUser(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed parse userID: %s", err.Error()), http.StatusUnauthorized)
+		return
+	}
+
+	path := strings.Trim(strings.TrimPrefix(r.URL.Path, workspacesPath), "/")
+	var segments []string
+	if len(path) != 0 {
+		segments = strings.Split(path, "/")
+	}
+
+	switch {
+	case len(segments) == 0:
+		h.controllerWorkspace(w, r, user)
+	case len(segments) >= 2 && segments[1] == "members":
+		h.controllerMembers(w, r, user, segments[0], segments[2:])
+	case len(segments) >= 2 && segments[1] == "urls":
+		h.controllerWorkspaceURLs(w, r, user, segments[0], segments[2:])
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (h *Handlers) controllerWorkspace(w http.ResponseWriter, r *http.Request, user User) {
+	// keys are scoped to links, workspaces are managed by account only
+	if len(user.APIKeyID) != 0 {
+		http.Error(w, "not allowed with api key", http.StatusForbidden)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPost:
+		var req models.WorkspaceRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, fmt.Sprintf("failed decode body: %s", err.Error()), http.StatusBadRequest)
+			return
+		}
+		workspace, err := h.service.CreateWorkspace(user.ID, req.Name)
+		if err != nil {
+			h.writeWorkspaceError(w, "CreateWorkspace", err)
+			return
+		}
+		h.writeJSON(w, workspace, http.StatusCreated)
+	case http.MethodGet:
+		workspaces, err := h.service.GetUserWorkspaces(user.ID)
+		if err != nil {
+			h.writeWorkspaceError(w, "GetUserWorkspaces", err)
+			return
+		}
+		h.writeJSON(w, workspaces, http.StatusOK)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (h *Handlers) controllerMembers(w http.ResponseWriter, r *http.Request, user User, workspaceID string, rest []string) {
+	if len(user.APIKeyID) != 0 {
+		http.Error(w, "not allowed with api key", http.StatusForbidden)
+		return
+	}
+
+	switch {
+	case len(rest) == 0 && r.Method == http.MethodGet:
+		members, err := h.service.GetWorkspaceMembers(user.ID, workspaceID)
+		if err != nil {
+			h.writeWorkspaceError(w, "GetWorkspaceMembers", err)
+			return
+		}
+		h.writeJSON(w, members, http.StatusOK)
+	case len(rest) == 0 && r.Method == http.MethodPost:
+		var req models.WorkspaceMemberRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, fmt.Sprintf("failed decode body: %s", err.Error()), http.StatusBadRequest)
+			return
+		}
+		member, err := h.service.SaveWorkspaceMember(user.ID, workspaceID, req.UserID, req.Role)
+		if err != nil {
+			h.writeWorkspaceError(w, "SaveWorkspaceMember", err)
+			return
+		}
+		h.writeJSON(w, member, http.StatusOK)
+	case len(rest) == 1 && r.Method == http.MethodDelete:
+		if err := h.service.DeleteWorkspaceMember(user.ID, workspaceID, rest[0]); err != nil {
+			h.writeWorkspaceError(w, "DeleteWorkspaceMember", err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	case len(rest) > 1:
+		http.NotFound(w, r)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (h *Handlers) controllerWorkspaceURLs(w http.ResponseWriter, r *http.Request, user User, workspaceID string, rest []string) {
+	switch {
+	case len(rest) == 0 && r.Method == http.MethodGet:
+		h.getWorkspaceURLs(w, user, workspaceID)
+	case len(rest) == 0 && r.Method == http.MethodPost:
+		h.createWorkspaceURL(w, r, user, workspaceID)
+	case len(rest) == 0 && r.Method == http.MethodDelete:
+		h.deleteWorkspaceURLs(w, r, user, workspaceID)
+	case len(rest) == 1 && r.Method == http.MethodPut:
+		h.updateWorkspaceURL(w, r, user, workspaceID, rest[0])
+	case len(rest) > 1:
+		http.NotFound(w, r)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (h *Handlers) getWorkspaceURLs(w http.ResponseWriter, user User, workspaceID string) {
+	if !user.HasScope(service.ScopeLinksRead) {
+		http.Error(w, "api key scope links:read required", http.StatusForbidden)
+		return
+	}
+
+	URLs, err := h.service.GetWorkspaceURLs(user.ID, workspaceID)
+	if err != nil {
+		h.writeWorkspaceError(w, "GetWorkspaceURLs", err)
+		return
+	}
+	for i, URL := range URLs {
+		URLs[i].ShortURL = fmt.Sprintf("%s/%s", h.baseURL, URL.ShortURL)
+	}
+	h.writeJSON(w, URLs, http.StatusOK)
+}
+
+// create link in workspace by url or move own personal link by short_url
+func (h *Handlers) createWorkspaceURL(w http.ResponseWriter, r *http.Request, user User, workspaceID string) {
+	if !user.HasScope(service.ScopeLinksWrite) {
+		http.Error(w, "api key scope links:write required", http.StatusForbidden)
+		return
+	}
+
+	var req models.WorkspaceURLRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, fmt.Sprintf("failed decode body: %s", err.Error()), http.StatusBadRequest)
+		return
+	}
+
+	if len(req.ShortURL) != 0 {
+		short := req.ShortURL[strings.LastIndex(req.ShortURL, "/")+1:]
+		if err := h.service.MoveURLToWorkspace(newActor(r, user), workspaceID, short); err != nil {
+			h.writeWorkspaceError(w, "MoveURLToWorkspace", err)
+			return
+		}
+		h.writeJSON(w, models.ShortenerResponse{ShortURL: fmt.Sprintf("%s/%s", h.baseURL, short)}, http.StatusOK)
+		return
+	}
+
+	statusCode := http.StatusCreated
+	short, err := h.service.CreateWorkspaceURL(r.Context(), newActor(r, user), workspaceID, req.URL)
+	if err != nil && errors.Is(err, service.ErrShortExists) {
+		statusCode = http.StatusConflict
+	} else if err != nil {
+		h.writeWorkspaceError(w, "CreateWorkspaceURL", err)
+		return
+	}
+	h.writeJSON(w, models.ShortenerResponse{ShortURL: fmt.Sprintf("%s/%s", h.baseURL, short)}, statusCode)
+}
+
+func (h *Handlers) updateWorkspaceURL(w http.ResponseWriter, r *http.Request, user User, workspaceID, short string) {
+	if !user.HasScope(service.ScopeLinksWrite) {
+		http.Error(w, "api key scope links:write required", http.StatusForbidden)
+		return
+	}
+
+	var req models.UpdateURLRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, fmt.Sprintf("failed decode body: %s", err.Error()), http.StatusBadRequest)
+		return
+	}
+	if err := h.service.UpdateWorkspaceURL(newActor(r, user), workspaceID, short, req.OriginalURL); err != nil {
+		h.writeWorkspaceError(w, "UpdateWorkspaceURL", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handlers) deleteWorkspaceURLs(w http.ResponseWriter, r *http.Request, user User, workspaceID string) {
+	if !user.HasScope(service.ScopeLinksDelete) {
+		http.Error(w, "api key scope links:delete required", http.StatusForbidden)
+		return
+	}
+
+	var shortURLs []string
+	if err := json.NewDecoder(r.Body).Decode(&shortURLs); err != nil {
+		http.Error(w, fmt.Sprintf("failed decode body: %s", err.Error()), http.StatusBadRequest)
+		return
+	}
+	if err := h.service.DeleteWorkspaceURLs(newActor(r, user), workspaceID, shortURLs); err != nil {
+		h.writeWorkspaceError(w, "DeleteWorkspaceURLs", err)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// map workspace service errors to status codes
+func (h *Handlers) writeWorkspaceError(w http.ResponseWriter, operation string, err error) {
+	switch {
+	case errors.Is(err, service.ErrWorkspaceNotExists), errors.Is(err, service.ErrMemberNotExists),
+		errors.Is(err, service.ErrWorkspaceURLNotExists), errors.Is(err, service.ErrUserURLsNotExists):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, service.ErrWorkspaceForbidden):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, service.ErrInvalidWorkspaceData):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, service.ErrLastWorkspaceOwner), errors.Is(err, service.ErrShortExists):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		h.logger.Info(operation+" service", zap.Error(err))
+		http.Error(w, fmt.Sprintf("service error: %s", err.Error()), http.StatusInternalServerError)
+	}
+}
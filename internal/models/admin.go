@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// AdminURL link with owner and state, visible only for admin
+type AdminURL struct {
+	ShortURL    string `json:"short_url"`
+	OriginalURL string `json:"original_url"`
+	UserID      string `json:"user_id"`
+	WorkspaceID string `json:"workspace_id,omitempty"`
+	Deleted     bool   `json:"is_deleted"`
+	Disabled    bool   `json:"is_disabled"`
+	Title       string `json:"title,omitempty"`
+	// empty for links created before creation time was stored
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+	RedirectPolicy
+}
+
+// AdminURLFilter search params, empty field matches any link, original is matched by substring
+type AdminURLFilter struct {
+	ShortURL    string
+	OriginalURL string
+	UserID      string
+	Limit       int
+}
+
+// AdminURLUpdate moderation of link, nil field is not changed
+type AdminURLUpdate struct {
+	Disabled *bool   `json:"disabled,omitempty"`
+	UserID   *string `json:"user_id,omitempty"`
+}
@@ -0,0 +1,64 @@
+package models
+
+import "time"
+
+// actions written to audit log
+const (
+	AuditLinkCreate      = "link.create"
+	AuditLinkBatchCreate = "link.batch_create"
+	AuditLinkUpdate      = "link.update"
+	AuditLinkDelete      = "link.delete"
+	AuditLinkRestore     = "link.restore"
+	AuditAdminDisable    = "admin.disable"
+	AuditAdminEnable     = "admin.enable"
+	AuditAdminReassign   = "admin.reassign"
+	AuditAdminDelete     = "admin.delete"
+)
+
+// Actor who made request, written to audit log with action
+type Actor struct {
+	UserID    string
+	RequestID string
+	IP        string
+}
+
+type AuditRecord struct {
+	ID        string `json:"id"`
+	ActorID   string `json:"actor_id"`
+	RequestID string `json:"request_id,omitempty"`
+	IP        string `json:"ip,omitempty"`
+	Action    string `json:"action"`
+	ShortURL  string `json:"short_url,omitempty"`
+	// changed value before and after action, for link it is original url
+	Before    string    `json:"before,omitempty"`
+	After     string    `json:"after,omitempty"`
+	Details   string    `json:"details,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// AuditFilter search params of audit log, zero field matches any record, range is [From, To)
+type AuditFilter struct {
+	From     time.Time
+	To       time.Time
+	ActorID  string
+	ShortURL string
+	Action   string
+	Limit    int
+}
+
+// Match check that record satisfies filter
+func (f AuditFilter) Match(record AuditRecord) bool {
+	switch {
+	case !f.From.IsZero() && record.CreatedAt.Before(f.From):
+		return false
+	case !f.To.IsZero() && !record.CreatedAt.Before(f.To):
+		return false
+	case len(f.ActorID) != 0 && record.ActorID != f.ActorID:
+		return false
+	case len(f.ShortURL) != 0 && record.ShortURL != f.ShortURL:
+		return false
+	case len(f.Action) != 0 && record.Action != f.Action:
+		return false
+	}
+	return true
+}
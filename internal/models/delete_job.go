@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// DeleteJob pending delete of user link, retried until done or dead
+type DeleteJob struct {
+	ID            string    `json:"id"`
+	UserID        string    `json:"user_id"`
+	ShortURL      string    `json:"short_url"`
+	OperationID   string    `json:"operation_id,omitempty"`
+	Attempts      int       `json:"attempts"`
+	NextAttemptAt time.Time `json:"next_attempt_at"`
+	LastError     string    `json:"last_error,omitempty"`
+	// moved to dead-letter list after last attempt, is not retried anymore
+	Dead      bool      `json:"is_dead"`
+	CreatedAt time.Time `json:"created_at"`
+}
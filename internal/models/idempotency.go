@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// IdempotencyRecord response of create request saved by idempotency key of user
+type IdempotencyRecord struct {
+	UserID string `json:"user_id"`
+	Key    string `json:"key"`
+	// hash of method, path and body of first request
+	RequestHash string `json:"request_hash"`
+	// zero while first request is handled
+	Status      int       `json:"status"`
+	ContentType string    `json:"content_type"`
+	Body        []byte    `json:"body"`
+	CreatedAt   time.Time `json:"created_at"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// Leader instance which holds leader lock
+type Leader struct {
+	Name       string    `json:"name"`
+	InstanceID string    `json:"instance_id"`
+	AcquiredAt time.Time `json:"acquired_at"`
+	RenewedAt  time.Time `json:"renewed_at"`
+}
+
+// LeaderStatus of election seen by instance which answers
+type LeaderStatus struct {
+	Name       string `json:"name"`
+	InstanceID string `json:"instance_id"`
+	IsLeader   bool   `json:"is_leader"`
+	// nil if nobody holds lock
+	Leader *Leader `json:"leader"`
+}
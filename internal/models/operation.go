@@ -0,0 +1,53 @@
+package models
+
+import "time"
+
+// status of delete operation and its links
+const (
+	OperationPending   = "pending"
+	OperationCompleted = "completed"
+	OperationFailed    = "failed"
+
+	DeleteResultPending  = "pending"
+	DeleteResultDeleted  = "deleted"
+	DeleteResultNotOwned = "not_owned"
+	DeleteResultNotFound = "not_found"
+	DeleteResultFailed   = "failed"
+)
+
+// DeleteOperation one delete request of user with result of every link
+type DeleteOperation struct {
+	ID     string `json:"id"`
+	UserID string `json:"user_id"`
+	// computed from results by OperationStatus
+	Status    string         `json:"status"`
+	Results   []DeleteResult `json:"results"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+}
+
+type DeleteResult struct {
+	ShortURL string `json:"short_url"`
+	Status   string `json:"status"`
+	Error    string `json:"error,omitempty"`
+}
+
+// DeleteOperationResponse returned on accepted delete request
+type DeleteOperationResponse struct {
+	OperationID string `json:"operation_id"`
+	Status      string `json:"status"`
+}
+
+// OperationStatus is pending while any link is pending, failed if any link failed, otherwise completed.
+func OperationStatus(results []DeleteResult) string {
+	status := OperationCompleted
+	for _, result := range results {
+		switch result.Status {
+		case DeleteResultPending:
+			return OperationPending
+		case DeleteResultFailed:
+			status = OperationFailed
+		}
+	}
+	return status
+}
@@ -1,14 +1,65 @@
 package models
 
+import "time"
+
 type ShortenerURL struct {
 	UserID      string `json:"user_id,omitempty"`
 	OriginalURL string `json:"original_url,omitempty"`
 	ShortURL    string `json:"short_url,omitempty"`
 	DeletedFlag bool   `json:"is_deleted,omitempty"`
+	// time of soft delete, link is purged after retention period
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+	// workspace which owns link, empty for personal link
+	WorkspaceID string `json:"workspace_id,omitempty"`
+	// link disabled by admin, redirect is not served
+	DisabledFlag bool `json:"is_disabled,omitempty"`
+	// set by batch save when original already has link, ShortURL is short of existing link
+	Conflict bool `json:"-"`
+	// shown on preview page, set by owner
+	Title string `json:"title,omitempty"`
+	// empty for links created before creation time was stored
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+	RedirectPolicy
+}
+
+// RedirectPolicy how redirect of link is served, zero fields use global defaults
+type RedirectPolicy struct {
+	// 301, 302, 307 or 308
+	RedirectStatus int `json:"redirect_status,omitempty"`
+	// value of Cache-Control header of redirect
+	CacheControl string `json:"cache_control,omitempty"`
+	// redirect is never cached so every visit reaches service
+	Tracked bool `json:"tracked,omitempty"`
+}
+
+// Redirect target and policy of active link, title and creation time are shown on preview page
+type Redirect struct {
+	OriginalURL string
+	Title       string
+	CreatedAt   *time.Time
+	RedirectPolicy
 }
 
 type ShortenerRequest struct {
-	URL string `json:"url"`
+	URL   string `json:"url"`
+	Title string `json:"title,omitempty"`
+	RedirectPolicy
+}
+
+// UserURLUpdateRequest change of link, only present fields are changed
+type UserURLUpdateRequest struct {
+	Title          *string `json:"title,omitempty"`
+	RedirectStatus *int    `json:"redirect_status,omitempty"`
+	CacheControl   *string `json:"cache_control,omitempty"`
+	Tracked        *bool   `json:"tracked,omitempty"`
+}
+
+// RedirectResponse link with its title and redirect policy
+type RedirectResponse struct {
+	ShortURL    string `json:"short_url"`
+	OriginalURL string `json:"original_url"`
+	Title       string `json:"title,omitempty"`
+	RedirectPolicy
 }
 
 type ShortenerResponse struct {
@@ -23,6 +74,10 @@ type BatchShortenerRequest struct {
 type BatchShortenerResponse struct {
 	CorrelationID string `json:"correlation_id"`
 	ShortURL      string `json:"short_url"`
+	// original already had short link, ShortURL is existing link
+	Conflict bool `json:"conflict"`
+	// set in ndjson stream for line which is not shortened
+	Error string `json:"error,omitempty"`
 }
 
 type URLResponse struct {
@@ -30,7 +85,52 @@ type URLResponse struct {
 	OriginalURL string `json:"original_url"`
 }
 
+// DeletedURLResponse link in trash of user
+type DeletedURLResponse struct {
+	ShortURL    string    `json:"short_url"`
+	OriginalURL string    `json:"original_url"`
+	DeletedAt   time.Time `json:"deleted_at"`
+}
+
 type DeleteURL struct {
 	UserID   string
 	ShortURL string
+	// delete operation which link belongs to
+	OperationID string
+}
+
+// Stats of service for internal monitoring
+type Stats struct {
+	URLs int `json:"urls"`
+	// distinct owners of links, anonymous users included
+	Users   int `json:"users"`
+	Deleted int `json:"deleted"`
+	// nil if redirect cache is disabled
+	Cache *CacheStats `json:"cache,omitempty"`
+	// nil if links are not stored in PostgreSQL
+	Pool *PoolStats `json:"pool,omitempty"`
+}
+
+// PoolStats of PostgreSQL connection pool
+type PoolStats struct {
+	TotalConns    int32 `json:"total_conns"`
+	AcquiredConns int32 `json:"acquired_conns"`
+	IdleConns     int32 `json:"idle_conns"`
+	MaxConns      int32 `json:"max_conns"`
+	AcquireCount  int64 `json:"acquire_count"`
+	// acquires which waited for free connection
+	EmptyAcquireCount    int64  `json:"empty_acquire_count"`
+	CanceledAcquireCount int64  `json:"canceled_acquire_count"`
+	AcquireDuration      string `json:"acquire_duration"`
+}
+
+// CacheStats of redirect cache since start
+type CacheStats struct {
+	Size int   `json:"size"`
+	Hits int64 `json:"hits"`
+	// hits of cached missing links
+	NegativeHits int64   `json:"negative_hits"`
+	Misses       int64   `json:"misses"`
+	Evictions    int64   `json:"evictions"`
+	HitRatio     float64 `json:"hit_ratio"`
 }
@@ -0,0 +1,56 @@
+package models
+
+import "time"
+
+// status of imported row
+const (
+	ImportCreated = "created"
+	// original already had link, existing link is returned
+	ImportExists  = "exists"
+	ImportInvalid = "invalid"
+	// deleted links are exported for backup but not imported
+	ImportSkipped = "skipped"
+)
+
+// ExportedURL link of user in export file, same fields are read on import
+type ExportedURL struct {
+	ShortURL    string     `json:"short_url"`
+	OriginalURL string     `json:"original_url"`
+	WorkspaceID string     `json:"workspace_id,omitempty"`
+	Deleted     bool       `json:"is_deleted"`
+	DeletedAt   *time.Time `json:"deleted_at,omitempty"`
+	Disabled    bool       `json:"is_disabled"`
+	Title       string     `json:"title,omitempty"`
+	CreatedAt   *time.Time `json:"created_at,omitempty"`
+	RedirectPolicy
+}
+
+// ImportRow parsed row of import file, numbered from 1
+type ImportRow struct {
+	Row         int
+	OriginalURL string
+	Deleted     bool
+	Title       string
+	// creation time of exported link, empty means time of import
+	CreatedAt *time.Time
+	RedirectPolicy
+	// error of parsing, row is reported as invalid
+	Error string
+}
+
+type ImportResult struct {
+	Row         int    `json:"row"`
+	OriginalURL string `json:"original_url"`
+	ShortURL    string `json:"short_url,omitempty"`
+	Status      string `json:"status"`
+	Error       string `json:"error,omitempty"`
+}
+
+// ImportReport result of every row with counters by status
+type ImportReport struct {
+	Created int            `json:"created"`
+	Exists  int            `json:"exists"`
+	Invalid int            `json:"invalid"`
+	Skipped int            `json:"skipped"`
+	Rows    []ImportResult `json:"rows"`
+}
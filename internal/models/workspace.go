@@ -0,0 +1,49 @@
+package models
+
+import "time"
+
+// roles of workspace members
+const (
+	RoleOwner  = "owner"
+	RoleEditor = "editor"
+	RoleViewer = "viewer"
+)
+
+type Workspace struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type WorkspaceMember struct {
+	WorkspaceID string    `json:"workspace_id"`
+	UserID      string    `json:"user_id"`
+	Role        string    `json:"role"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type WorkspaceRequest struct {
+	Name string `json:"name"`
+}
+
+type WorkspaceResponse struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type WorkspaceMemberRequest struct {
+	UserID string `json:"user_id"`
+	Role   string `json:"role"`
+}
+
+// WorkspaceURLRequest create link with URL or move own personal link ShortURL to workspace
+type WorkspaceURLRequest struct {
+	URL      string `json:"url,omitempty"`
+	ShortURL string `json:"short_url,omitempty"`
+}
+
+type UpdateURLRequest struct {
+	OriginalURL string `json:"original_url"`
+}
@@ -0,0 +1,173 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/hollgett/shortener.git/internal/models"
+	"github.com/hollgett/shortener.git/internal/store"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultAdminLimit = 100
+	maxAdminLimit     = 1000
+)
+
+var (
+	ErrNotAdmin         = errors.New("admin rights required")
+	ErrURLNotExists     = errors.New("short url doesn't exist")
+	ErrInvalidAdminData = errors.New("invalid admin request data")
+)
+
+type AdminStore interface {
+	SearchURLs(filter models.AdminURLFilter) ([]models.AdminURL, error)
+	GetURL(shortURL string) (models.AdminURL, error)
+	GetURLs(shortURLs []string) ([]models.AdminURL, error)
+	SetURLDisabled(shortURL string, disabled bool) error
+	ReassignURL(shortURL, userID string) error
+	HardDeleteURL(shortURL string) error
+	GetDeadDeleteJobs(limit int) ([]models.DeleteJob, error)
+}
+
+// IsAdmin check that user is registered with password and his login is in admin list.
+//
+// accounts of identity provider have no password, their login is not proven by user itself.
+func (s *Service) IsAdmin(userID string) (bool, error) {
+	if len(s.admins) == 0 {
+		return false, nil
+	}
+	user, err := s.store.GetUserByID(userID)
+	if err != nil && errors.Is(err, store.ErrUserNotExists) {
+		return false, nil
+	} else if err != nil {
+		return false, fmt.Errorf("GetUserByID store error: %w", err)
+	}
+	if len(user.PasswordHash) == 0 {
+		return false, nil
+	}
+	_, ok := s.admins[user.Login]
+	return ok, nil
+}
+
+// SearchURLs find links of all users by filter.
+func (s *Service) SearchURLs(adminID string, filter models.AdminURLFilter) ([]models.AdminURL, error) {
+	if err := s.requireAdmin(adminID); err != nil {
+		return nil, err
+	}
+	switch {
+	case filter.Limit <= 0:
+		filter.Limit = defaultAdminLimit
+	case filter.Limit > maxAdminLimit:
+		filter.Limit = maxAdminLimit
+	}
+
+	URLs, err := s.store.SearchURLs(filter)
+	if err != nil {
+		return nil, fmt.Errorf("SearchURLs store error: %w", err)
+	}
+	return URLs, nil
+}
+
+// GetDeadDeleteJobs return deletes of links failed after all attempts, newest first.
+func (s *Service) GetDeadDeleteJobs(adminID string, limit int) ([]models.DeleteJob, error) {
+	if err := s.requireAdmin(adminID); err != nil {
+		return nil, err
+	}
+	if limit <= 0 || limit > maxAdminLimit {
+		limit = defaultAdminLimit
+	}
+	jobs, err := s.store.GetDeadDeleteJobs(limit)
+	if err != nil {
+		return nil, fmt.Errorf("GetDeadDeleteJobs store error: %w", err)
+	}
+	return jobs, nil
+}
+
+func (s *Service) GetURL(adminID, shortURL string) (models.AdminURL, error) {
+	if err := s.requireAdmin(adminID); err != nil {
+		return models.AdminURL{}, err
+	}
+	return s.getURL(shortURL)
+}
+
+// ModerateURL disable or enable link and reassign its owner, every change is written to audit log.
+func (s *Service) ModerateURL(actor models.Actor, shortURL string, update models.AdminURLUpdate) (models.AdminURL, error) {
+	s.logger.Info("ModerateURL", zap.String("admin", actor.UserID), zap.String("short", shortURL))
+	if update.Disabled == nil && update.UserID == nil {
+		return models.AdminURL{}, ErrInvalidAdminData
+	}
+	if update.UserID != nil && len(*update.UserID) == 0 {
+		return models.AdminURL{}, fmt.Errorf("%w: empty user id", ErrInvalidAdminData)
+	}
+	if err := s.requireAdmin(actor.UserID); err != nil {
+		return models.AdminURL{}, err
+	}
+	URL, err := s.getURL(shortURL)
+	if err != nil {
+		return models.AdminURL{}, err
+	}
+
+	if update.Disabled != nil && *update.Disabled != URL.Disabled {
+		if err := s.store.SetURLDisabled(shortURL, *update.Disabled); err != nil {
+			return models.AdminURL{}, fmt.Errorf("SetURLDisabled store error: %w", err)
+		}
+		action, before, after := models.AuditAdminEnable, "disabled", "enabled"
+		if *update.Disabled {
+			action, before, after = models.AuditAdminDisable, after, before
+		}
+		s.audit(actor, action, shortURL, before, after, "")
+		URL.Disabled = *update.Disabled
+	}
+
+	if update.UserID != nil && *update.UserID != URL.UserID {
+		if err := s.store.ReassignURL(shortURL, *update.UserID); err != nil {
+			return models.AdminURL{}, fmt.Errorf("ReassignURL store error: %w", err)
+		}
+		s.audit(actor, models.AuditAdminReassign, shortURL, URL.UserID, *update.UserID, "owner")
+		URL.UserID = *update.UserID
+	}
+	return URL, nil
+}
+
+// DeleteURL remove link completely, original url is kept in audit log.
+func (s *Service) DeleteURL(actor models.Actor, shortURL string) error {
+	s.logger.Info("DeleteURL", zap.String("admin", actor.UserID), zap.String("short", shortURL))
+	if err := s.requireAdmin(actor.UserID); err != nil {
+		return err
+	}
+	URL, err := s.getURL(shortURL)
+	if err != nil {
+		return err
+	}
+
+	err = s.store.HardDeleteURL(shortURL)
+	if err != nil && errors.Is(err, store.ErrIsNotExists) {
+		return ErrURLNotExists
+	} else if err != nil {
+		return fmt.Errorf("HardDeleteURL store error: %w", err)
+	}
+	s.audit(actor, models.AuditAdminDelete, shortURL, URL.OriginalURL, "", "owner "+URL.UserID)
+	return nil
+}
+
+func (s *Service) requireAdmin(userID string) error {
+	ok, err := s.IsAdmin(userID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrNotAdmin
+	}
+	return nil
+}
+
+func (s *Service) getURL(shortURL string) (models.AdminURL, error) {
+	URL, err := s.store.GetURL(shortURL)
+	if err != nil && errors.Is(err, store.ErrIsNotExists) {
+		return models.AdminURL{}, ErrURLNotExists
+	} else if err != nil {
+		return models.AdminURL{}, fmt.Errorf("GetURL store error: %w", err)
+	}
+	return URL, nil
+}
@@ -0,0 +1,142 @@
+package service
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/hollgett/shortener.git/internal/models"
+	"github.com/hollgett/shortener.git/internal/store"
+	"go.uber.org/zap"
+)
+
+// scopes of api keys
+const (
+	ScopeLinksWrite  = "links:write"
+	ScopeLinksRead   = "links:read"
+	ScopeLinksDelete = "links:delete"
+)
+
+const (
+	// APIKeyPrefix start of every api key, key format is "shk_<id>_<secret>"
+	APIKeyPrefix    = "shk_"
+	lenAPIKeyID     = 6
+	lenAPIKeySecret = 24
+	maxLenKeyName   = 255
+)
+
+var (
+	ErrInvalidAPIKey     = errors.New("invalid api key")
+	ErrAPIKeyNotExists   = errors.New("api key doesn't exist")
+	ErrInvalidAPIKeyData = errors.New("invalid api key data")
+)
+
+var knownScopes = []string{ScopeLinksWrite, ScopeLinksRead, ScopeLinksDelete}
+
+type APIKeyStore interface {
+	CreateAPIKey(key models.APIKey) error
+	GetAPIKey(keyID string) (models.APIKey, error)
+	GetUserAPIKeys(userID string) ([]models.APIKey, error)
+	RevokeAPIKey(userID, keyID string) error
+	TouchAPIKey(keyID string, usedAt time.Time) error
+}
+
+// CreateAPIKey create api key for user and return it with plain key, plain key can't be restored later.
+func (s *Service) CreateAPIKey(userID, name string, scopes []string, expiresAt *time.Time) (models.APIKey, string, error) {
+	s.logger.Info("CreateAPIKey", zap.String("user id", userID), zap.Strings("scopes", scopes))
+	if err := validateAPIKey(name, scopes, expiresAt); err != nil {
+		return models.APIKey{}, "", err
+	}
+
+	secret := generateSecret(lenAPIKeySecret)
+	key := models.APIKey{
+		ID:        generateSecret(lenAPIKeyID),
+		UserID:    userID,
+		Name:      name,
+		Hash:      hashAPIKeySecret(secret),
+		Scopes:    slices.Compact(slices.Sorted(slices.Values(scopes))),
+		CreatedAt: time.Now().UTC(),
+		ExpiresAt: expiresAt,
+	}
+	if err := s.store.CreateAPIKey(key); err != nil {
+		return models.APIKey{}, "", fmt.Errorf("CreateAPIKey store error: %w", err)
+	}
+
+	return key, APIKeyPrefix + key.ID + "_" + secret, nil
+}
+
+func (s *Service) GetUserAPIKeys(userID string) ([]models.APIKey, error) {
+	keys, err := s.store.GetUserAPIKeys(userID)
+	if err != nil {
+		return nil, fmt.Errorf("GetUserAPIKeys store error: %w", err)
+	}
+	return keys, nil
+}
+
+func (s *Service) RevokeAPIKey(userID, keyID string) error {
+	err := s.store.RevokeAPIKey(userID, keyID)
+	if err != nil && errors.Is(err, store.ErrAPIKeyNotExists) {
+		return ErrAPIKeyNotExists
+	} else if err != nil {
+		return fmt.Errorf("RevokeAPIKey store error: %w", err)
+	}
+	return nil
+}
+
+// AuthenticateAPIKey check plain key, revocation and expiry, and update last used time.
+func (s *Service) AuthenticateAPIKey(plainKey string) (models.APIKey, error) {
+	keyID, secret, ok := strings.Cut(strings.TrimPrefix(plainKey, APIKeyPrefix), "_")
+	if !ok || !strings.HasPrefix(plainKey, APIKeyPrefix) {
+		return models.APIKey{}, ErrInvalidAPIKey
+	}
+
+	key, err := s.store.GetAPIKey(keyID)
+	if err != nil && errors.Is(err, store.ErrAPIKeyNotExists) {
+		return models.APIKey{}, ErrInvalidAPIKey
+	} else if err != nil {
+		return models.APIKey{}, fmt.Errorf("GetAPIKey store error: %w", err)
+	}
+
+	if subtle.ConstantTimeCompare([]byte(key.Hash), []byte(hashAPIKeySecret(secret))) != 1 {
+		return models.APIKey{}, ErrInvalidAPIKey
+	}
+	now := time.Now().UTC()
+	if key.Revoked || (key.ExpiresAt != nil && now.After(*key.ExpiresAt)) {
+		return models.APIKey{}, ErrInvalidAPIKey
+	}
+
+	if err := s.store.TouchAPIKey(key.ID, now); err != nil {
+		s.logger.Info("TouchAPIKey store", zap.Error(err))
+	}
+	key.LastUsedAt = &now
+	return key, nil
+}
+
+// api keys have high entropy secret, so plain sha256 is enough for storing
+func hashAPIKeySecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+func validateAPIKey(name string, scopes []string, expiresAt *time.Time) error {
+	if len(name) > maxLenKeyName {
+		return fmt.Errorf("%w: name length must be less than %d", ErrInvalidAPIKeyData, maxLenKeyName)
+	}
+	if len(scopes) == 0 {
+		return fmt.Errorf("%w: at least one scope required", ErrInvalidAPIKeyData)
+	}
+	for _, scope := range scopes {
+		if !slices.Contains(knownScopes, scope) {
+			return fmt.Errorf("%w: unknown scope %q", ErrInvalidAPIKeyData, scope)
+		}
+	}
+	if expiresAt != nil && expiresAt.Before(time.Now()) {
+		return fmt.Errorf("%w: expires_at in the past", ErrInvalidAPIKeyData)
+	}
+	return nil
+}
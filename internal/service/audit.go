@@ -0,0 +1,56 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hollgett/shortener.git/internal/models"
+	"go.uber.org/zap"
+)
+
+const lenAuditRecordID = 16
+
+type AuditStore interface {
+	SaveAuditRecord(record models.AuditRecord) error
+	GetAuditRecords(filter models.AuditFilter) ([]models.AuditRecord, error)
+}
+
+// GetAuditRecords return newest records of audit log matched by filter.
+func (s *Service) GetAuditRecords(adminID string, filter models.AuditFilter) ([]models.AuditRecord, error) {
+	if err := s.requireAdmin(adminID); err != nil {
+		return nil, err
+	}
+	if !filter.From.IsZero() && !filter.To.IsZero() && !filter.From.Before(filter.To) {
+		return nil, fmt.Errorf("%w: from must be before to", ErrInvalidAdminData)
+	}
+	if filter.Limit <= 0 || filter.Limit > maxAdminLimit {
+		filter.Limit = defaultAdminLimit
+	}
+	records, err := s.store.GetAuditRecords(filter)
+	if err != nil {
+		return nil, fmt.Errorf("GetAuditRecords store error: %w", err)
+	}
+	return records, nil
+}
+
+// write action of actor to audit log, before and after are changed values.
+//
+// audit is written after change is committed, so failed write is only logged. Returned error would make
+// client retry committed change and create duplicate.
+func (s *Service) audit(actor models.Actor, action, shortURL, before, after, details string) {
+	record := models.AuditRecord{
+		ID:        generateSecret(lenAuditRecordID),
+		ActorID:   actor.UserID,
+		RequestID: actor.RequestID,
+		IP:        actor.IP,
+		Action:    action,
+		ShortURL:  shortURL,
+		Before:    before,
+		After:     after,
+		Details:   details,
+		CreatedAt: time.Now().UTC(),
+	}
+	if err := s.store.SaveAuditRecord(record); err != nil {
+		s.logger.Error("SaveAuditRecord", zap.Error(err), zap.String("action", action), zap.String("short", shortURL))
+	}
+}
@@ -0,0 +1,100 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/hollgett/shortener.git/internal/models"
+	"github.com/hollgett/shortener.git/internal/store"
+)
+
+// key of request which is still handled is taken by next request after lease,
+// so key of request lost by crashed instance is not locked for whole window
+const idempotencyLease = 30 * time.Second
+
+var (
+	ErrIdempotencyKeyMismatch   = errors.New("idempotency key is used with different request")
+	ErrIdempotencyKeyInProgress = errors.New("request with idempotency key is in progress")
+)
+
+type IdempotencyStore interface {
+	SaveIdempotencyKey(record models.IdempotencyRecord) error
+	GetIdempotencyKey(userID, key string) (models.IdempotencyRecord, error)
+	CompleteIdempotencyKey(record models.IdempotencyRecord) error
+	DeleteIdempotencyKey(userID, key string) error
+	PurgeIdempotencyKeys(expiredBefore time.Time) (int, error)
+}
+
+// IdempotencyEnabled report if responses are saved by idempotency keys
+func (s *Service) IdempotencyEnabled() bool {
+	return s.idempotencyTTL > 0
+}
+
+// BeginIdempotent reserve idempotency key of user for request, return saved record and true if request was handled already.
+//
+// key reused with other request returns ErrIdempotencyKeyMismatch, key of request which is still handled returns ErrIdempotencyKeyInProgress.
+// Reserved key expires after lease and gets whole window when response is saved.
+func (s *Service) BeginIdempotent(userID, key, requestHash string) (models.IdempotencyRecord, bool, error) {
+	// creation time identifies reservation, it is kept in precision of database
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	record := models.IdempotencyRecord{
+		UserID:      userID,
+		Key:         key,
+		RequestHash: requestHash,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(min(idempotencyLease, s.idempotencyTTL)),
+	}
+	err := s.store.SaveIdempotencyKey(record)
+	if err == nil {
+		return record, false, nil
+	} else if !errors.Is(err, store.ErrIdempotencyKeyExists) {
+		return models.IdempotencyRecord{}, false, fmt.Errorf("SaveIdempotencyKey store error: %w", err)
+	}
+
+	saved, err := s.store.GetIdempotencyKey(userID, key)
+	if err != nil {
+		return models.IdempotencyRecord{}, false, fmt.Errorf("GetIdempotencyKey store error: %w", err)
+	}
+	switch {
+	case saved.RequestHash != requestHash:
+		return models.IdempotencyRecord{}, false, ErrIdempotencyKeyMismatch
+	case saved.Status == 0:
+		return models.IdempotencyRecord{}, false, ErrIdempotencyKeyInProgress
+	}
+	return saved, true, nil
+}
+
+// CompleteIdempotent save response of request, key is released on server error so request can be retried.
+func (s *Service) CompleteIdempotent(record models.IdempotencyRecord, status int, contentType string, body []byte) error {
+	if status >= http.StatusInternalServerError {
+		return s.ReleaseIdempotent(record)
+	}
+	if body == nil {
+		body = []byte{}
+	}
+	record.Status, record.ContentType, record.Body = status, contentType, body
+	record.ExpiresAt = record.CreatedAt.Add(s.idempotencyTTL)
+	if err := s.store.CompleteIdempotencyKey(record); err != nil {
+		return fmt.Errorf("CompleteIdempotencyKey store error: %w", err)
+	}
+	return nil
+}
+
+// ReleaseIdempotent delete reserved key of request which failed without response, so request can be retried.
+func (s *Service) ReleaseIdempotent(record models.IdempotencyRecord) error {
+	if err := s.store.DeleteIdempotencyKey(record.UserID, record.Key); err != nil {
+		return fmt.Errorf("DeleteIdempotencyKey store error: %w", err)
+	}
+	return nil
+}
+
+// PurgeIdempotencyKeys delete expired idempotency keys.
+func (s *Service) PurgeIdempotencyKeys() (int, error) {
+	purged, err := s.store.PurgeIdempotencyKeys(time.Now().UTC())
+	if err != nil {
+		return 0, fmt.Errorf("PurgeIdempotencyKeys store error: %w", err)
+	}
+	return purged, nil
+}
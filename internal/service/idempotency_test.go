@@ -0,0 +1,63 @@
+package service
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/hollgett/shortener.git/internal/logger"
+	"github.com/hollgett/shortener.git/internal/models"
+	"github.com/hollgett/shortener.git/internal/store"
+)
+
+func TestIdempotentLease(t *testing.T) {
+	log, err := logger.NewLogger()
+	if err != nil {
+		t.Fatal(err)
+	}
+	memory := store.NewInMemoryStore(store.DedupGlobal)
+	s := NewService(log, memory, nil, nil, nil, time.Hour)
+
+	// key of request lost after lease is taken by next request
+	now := time.Now().UTC()
+	lost := models.IdempotencyRecord{UserID: "u", Key: "lost", RequestHash: "h",
+		CreatedAt: now.Add(-2 * idempotencyLease), ExpiresAt: now.Add(-idempotencyLease)}
+	if err := memory.SaveIdempotencyKey(lost); err != nil {
+		t.Fatal(err)
+	}
+	record, replay, err := s.BeginIdempotent("u", "lost", "h")
+	if err != nil || replay {
+		t.Fatalf("BeginIdempotent() after lease = %v, %v, want new reservation", replay, err)
+	}
+	if err := s.CompleteIdempotent(lost, http.StatusCreated, "", nil); err == nil {
+		t.Error("CompleteIdempotent() of lost request error = nil, want key of other request is kept")
+	}
+
+	// key of request in progress is not taken, saved response gets whole window
+	if _, _, err := s.BeginIdempotent("u", "lost", "h"); !errors.Is(err, ErrIdempotencyKeyInProgress) {
+		t.Errorf("BeginIdempotent() during lease error = %v, want %v", err, ErrIdempotencyKeyInProgress)
+	}
+	if err := s.CompleteIdempotent(record, http.StatusCreated, "text/plain", []byte("ok")); err != nil {
+		t.Fatalf("CompleteIdempotent() error = %v", err)
+	}
+	saved, replay, err := s.BeginIdempotent("u", "lost", "h")
+	if err != nil || !replay || string(saved.Body) != "ok" {
+		t.Errorf("BeginIdempotent() after complete = %+v, %v, %v, want replay", saved, replay, err)
+	}
+	if want := record.CreatedAt.Add(time.Hour); !saved.ExpiresAt.Equal(want) {
+		t.Errorf("expires at = %v, want %v", saved.ExpiresAt, want)
+	}
+
+	// released key is reserved again
+	record, _, err = s.BeginIdempotent("u", "released", "h")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.ReleaseIdempotent(record); err != nil {
+		t.Fatalf("ReleaseIdempotent() error = %v", err)
+	}
+	if _, replay, err := s.BeginIdempotent("u", "released", "h"); err != nil || replay {
+		t.Errorf("BeginIdempotent() after release = %v, %v, want new reservation", replay, err)
+	}
+}
@@ -0,0 +1,37 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/hollgett/shortener.git/internal/models"
+	"github.com/hollgett/shortener.git/internal/store"
+)
+
+type LeaderStore interface {
+	GetLeader(name string) (models.Leader, error)
+}
+
+// Leadership of this instance in election for scheduled jobs
+type Leadership interface {
+	LockName() string
+	InstanceID() string
+	IsLeader() bool
+}
+
+// GetLeaderStatus return current leader of scheduled jobs and state of this instance.
+func (s *Service) GetLeaderStatus() (models.LeaderStatus, error) {
+	status := models.LeaderStatus{
+		Name:       s.leadership.LockName(),
+		InstanceID: s.leadership.InstanceID(),
+		IsLeader:   s.leadership.IsLeader(),
+	}
+	leader, err := s.store.GetLeader(status.Name)
+	if err != nil && errors.Is(err, store.ErrLeaderNotExists) {
+		return status, nil
+	} else if err != nil {
+		return models.LeaderStatus{}, fmt.Errorf("GetLeader store error: %w", err)
+	}
+	status.Leader = &leader
+	return status, nil
+}
@@ -0,0 +1,103 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hollgett/shortener.git/internal/models"
+	"github.com/hollgett/shortener.git/internal/store"
+	"go.uber.org/zap"
+)
+
+// login prefix of accounts created by identity provider, keeps them apart from registered logins
+const externalLoginPrefix = "oidc:"
+
+// login of account created by identity provider, hash fits login column for any subject
+// and keeps equal subjects of different issuers apart. Issuer is url, so it has no spaces.
+func externalLogin(issuer, subject string) string {
+	sum := sha256.Sum256([]byte(issuer + " " + subject))
+	return externalLoginPrefix + hex.EncodeToString(sum[:])
+}
+
+// LoginExternalUser map subject of identity provider to user and open session.
+//
+// known subject login to linked account and claim URLs of anonymous current user. Unknown subject is linked
+// to current account if linkToCurrent is true, otherwise new account created with current anonymous user id.
+func (s *Service) LoginExternalUser(currentUserID string, linkToCurrent bool, issuer, subject, email string) (models.User, models.Session, error) {
+	s.logger.Info("LoginExternalUser", zap.String("issuer", issuer), zap.String("subject", subject))
+
+	identity, err := s.store.GetUserIdentity(issuer, subject)
+	switch {
+	case err == nil:
+		user, err := s.store.GetUserByID(identity.UserID)
+		if err != nil {
+			return models.User{}, models.Session{}, fmt.Errorf("GetUserByID store error: %w", err)
+		}
+		if !linkToCurrent {
+			if err := s.claimAnonymousURLs(currentUserID, user.ID); err != nil {
+				return models.User{}, models.Session{}, err
+			}
+		}
+		return s.openUserSession(user)
+	case !errors.Is(err, store.ErrIdentityNotExists):
+		return models.User{}, models.Session{}, fmt.Errorf("GetUserIdentity store error: %w", err)
+	}
+
+	var user models.User
+	if linkToCurrent {
+		if user, err = s.store.GetUserByID(currentUserID); err != nil {
+			return models.User{}, models.Session{}, fmt.Errorf("GetUserByID store error: %w", err)
+		}
+	} else {
+		if user, err = s.createExternalUser(currentUserID, issuer, subject); err != nil {
+			return models.User{}, models.Session{}, err
+		}
+	}
+
+	err = s.store.CreateUserIdentity(models.UserIdentity{
+		Issuer:    issuer,
+		Subject:   subject,
+		UserID:    user.ID,
+		Email:     email,
+		CreatedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		return models.User{}, models.Session{}, fmt.Errorf("CreateUserIdentity store error: %w", err)
+	}
+	return s.openUserSession(user)
+}
+
+// create account without password for anonymous user, login is built from issuer and subject of provider.
+//
+// email is not used as login, otherwise provider account could take login of admin or registered user.
+func (s *Service) createExternalUser(anonymousID, issuer, subject string) (models.User, error) {
+	if _, err := s.store.GetUserByID(anonymousID); err == nil {
+		return models.User{}, ErrAlreadyRegistered
+	} else if !errors.Is(err, store.ErrUserNotExists) {
+		return models.User{}, fmt.Errorf("GetUserByID store error: %w", err)
+	}
+
+	user := models.User{
+		ID:        anonymousID,
+		Login:     externalLogin(issuer, subject),
+		CreatedAt: time.Now().UTC(),
+	}
+	err := s.store.CreateUser(user)
+	if errors.Is(err, store.ErrUserExists) {
+		return models.User{}, ErrUserExists
+	} else if err != nil {
+		return models.User{}, fmt.Errorf("CreateUser store error: %w", err)
+	}
+	return user, nil
+}
+
+func (s *Service) openUserSession(user models.User) (models.User, models.Session, error) {
+	session, err := s.openSession(user.ID)
+	if err != nil {
+		return models.User{}, models.Session{}, err
+	}
+	return user, session, nil
+}
@@ -0,0 +1,20 @@
+package service
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExternalLogin(t *testing.T) {
+	// same value is built by migrations 000019 and 000021
+	want := "oidc:1bb73f06583b9dd053f5c68c1e9c34cb8932cd3067d0d2f687ac6397cbf4dd41"
+	if got := externalLogin("https://idp.example", "sub-1"); got != want {
+		t.Errorf("externalLogin() = %q, want %q", got, want)
+	}
+	if externalLogin("https://a.example", "sub-1") == externalLogin("https://b.example", "sub-1") {
+		t.Error("externalLogin() is same for different issuers")
+	}
+	if got := externalLogin("https://idp.example", strings.Repeat("s", 255)); len(got) > 255 {
+		t.Errorf("externalLogin() length = %d, want at most 255", len(got))
+	}
+}
@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/hollgett/shortener.git/internal/models"
+	"github.com/hollgett/shortener.git/internal/store"
+	"go.uber.org/zap"
+)
+
+const (
+	// max length of Cache-Control value of link
+	maxCacheControlLen = 255
+	// max count of characters in title of link
+	maxTitleLen = 255
+)
+
+var (
+	ErrInvalidRedirect = errors.New("invalid redirect policy")
+	ErrInvalidTitle    = errors.New("invalid title of link")
+)
+
+type RedirectStore interface {
+	GetRedirect(ctx context.Context, shortURL string) (models.Redirect, error)
+	SetURLRedirect(shortURL string, policy models.RedirectPolicy) error
+	SetURLTitle(shortURL, title string) error
+}
+
+// ValidateRedirectPolicy check status is redirect kind and cache control can be written as header value,
+// zero status is allowed and means default status.
+func ValidateRedirectPolicy(policy models.RedirectPolicy) error {
+	switch policy.RedirectStatus {
+	case 0, http.StatusMovedPermanently, http.StatusFound, http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+	default:
+		return fmt.Errorf("%w: status %d is not supported", ErrInvalidRedirect, policy.RedirectStatus)
+	}
+	if len(policy.CacheControl) > maxCacheControlLen {
+		return fmt.Errorf("%w: cache control is longer than %d", ErrInvalidRedirect, maxCacheControlLen)
+	}
+	if strings.ContainsFunc(policy.CacheControl, unicode.IsControl) {
+		return fmt.Errorf("%w: cache control contains control characters", ErrInvalidRedirect)
+	}
+	return nil
+}
+
+// check title can be shown on preview page
+func validateTitle(title string) error {
+	if utf8.RuneCountInString(title) > maxTitleLen {
+		return fmt.Errorf("%w: longer than %d", ErrInvalidTitle, maxTitleLen)
+	}
+	if !utf8.ValidString(title) || strings.ContainsFunc(title, unicode.IsControl) {
+		return fmt.Errorf("%w: contains invalid characters", ErrInvalidTitle)
+	}
+	return nil
+}
+
+// GetRedirect return target and policy of active link
+func (s *Service) GetRedirect(ctx context.Context, shortLink string) (models.Redirect, error) {
+	s.logger.Info("GetRedirect", zap.String("short", shortLink))
+	redirect, err := s.store.GetRedirect(ctx, shortLink)
+	if err != nil && errors.Is(err, store.ErrURLDeleted) {
+		s.logger.Info("GetRedirect", zap.Error(err))
+		return models.Redirect{}, ErrURLDeleted
+	} else if err != nil && errors.Is(err, store.ErrURLDisabled) {
+		s.logger.Info("GetRedirect", zap.Error(err))
+		return models.Redirect{}, ErrURLDisabled
+	} else if err != nil {
+		s.logger.Info("GetRedirect", zap.Error(err))
+		return models.Redirect{}, fmt.Errorf("GetRedirect store err: %w", err)
+	}
+
+	s.logger.Info("GetRedirect", zap.String("original", redirect.OriginalURL))
+	return redirect, nil
+}
+
+// UpdateUserURL change redirect policy and title of own personal link or link of workspace where actor is editor,
+// fields missing in request keep their values.
+func (s *Service) UpdateUserURL(actor models.Actor, shortURL string, req models.UserURLUpdateRequest) (models.RedirectResponse, error) {
+	s.logger.Info("UpdateUserURL", zap.String("user id", actor.UserID), zap.String("short", shortURL))
+	if req.Title != nil {
+		if err := validateTitle(*req.Title); err != nil {
+			return models.RedirectResponse{}, err
+		}
+	}
+	URL, err := s.store.GetURL(shortURL)
+	if err != nil && errors.Is(err, store.ErrIsNotExists) {
+		return models.RedirectResponse{}, ErrUserURLsNotExists
+	} else if err != nil {
+		return models.RedirectResponse{}, fmt.Errorf("GetURL store error: %w", err)
+	}
+	switch {
+	case URL.Deleted:
+		return models.RedirectResponse{}, ErrURLDeleted
+	case len(URL.WorkspaceID) != 0:
+		if err := s.requireRole(actor.UserID, URL.WorkspaceID, models.RoleEditor); err != nil {
+			return models.RedirectResponse{}, err
+		}
+	case URL.UserID != actor.UserID:
+		return models.RedirectResponse{}, ErrUserURLsNotExists
+	}
+
+	policy := URL.RedirectPolicy
+	if req.RedirectStatus != nil {
+		policy.RedirectStatus = *req.RedirectStatus
+	}
+	if req.CacheControl != nil {
+		policy.CacheControl = *req.CacheControl
+	}
+	if req.Tracked != nil {
+		policy.Tracked = *req.Tracked
+	}
+	if err := ValidateRedirectPolicy(policy); err != nil {
+		return models.RedirectResponse{}, err
+	}
+
+	if policy != URL.RedirectPolicy {
+		err = s.store.SetURLRedirect(shortURL, policy)
+		if err != nil && errors.Is(err, store.ErrIsNotExists) {
+			return models.RedirectResponse{}, ErrUserURLsNotExists
+		} else if err != nil {
+			return models.RedirectResponse{}, fmt.Errorf("SetURLRedirect store error: %w", err)
+		}
+		s.audit(actor, models.AuditLinkUpdate, shortURL, formatRedirectPolicy(URL.RedirectPolicy),
+			formatRedirectPolicy(policy), "redirect")
+	}
+
+	title := URL.Title
+	if req.Title != nil && *req.Title != URL.Title {
+		title = *req.Title
+		err = s.store.SetURLTitle(shortURL, title)
+		if err != nil && errors.Is(err, store.ErrIsNotExists) {
+			return models.RedirectResponse{}, ErrUserURLsNotExists
+		} else if err != nil {
+			return models.RedirectResponse{}, fmt.Errorf("SetURLTitle store error: %w", err)
+		}
+		s.audit(actor, models.AuditLinkUpdate, shortURL, URL.Title, title, "title")
+	}
+	return models.RedirectResponse{ShortURL: shortURL, OriginalURL: URL.OriginalURL, Title: title, RedirectPolicy: policy}, nil
+}
+
+// policy in audit record
+func formatRedirectPolicy(policy models.RedirectPolicy) string {
+	return fmt.Sprintf("status=%d cache_control=%q tracked=%t", policy.RedirectStatus, policy.CacheControl, policy.Tracked)
+}
@@ -1,8 +1,10 @@
 package service
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/hollgett/shortener.git/internal/logger"
 	"github.com/hollgett/shortener.git/internal/models"
@@ -11,48 +13,99 @@ import (
 )
 
 var (
-	ErrShortExists       = errors.New("short link exist in database")
-	ErrUserURLsNotExists = errors.New("url with user doesn't exist in database")
-	ErrURLDeleted        = errors.New("short url deleted")
+	ErrShortExists        = errors.New("short link exist in database")
+	ErrUserURLsNotExists  = errors.New("url with user doesn't exist in database")
+	ErrURLDeleted         = errors.New("short url deleted")
+	ErrURLDisabled        = errors.New("short url disabled by admin")
+	ErrOperationNotExists = errors.New("delete operation doesn't exist")
 )
 
 type Store interface {
-	SaveShortURL(URL models.ShortenerURL) (string, error)
-	SaveShortURLs(URLs []models.ShortenerURL) ([]models.ShortenerURL, error)
-	GetOriginalURL(ShortLink string) (string, error)
+	SaveShortURL(ctx context.Context, URL models.ShortenerURL) (string, error)
+	SaveShortURLs(ctx context.Context, URLs []models.ShortenerURL) ([]models.ShortenerURL, error)
 	GetUserURLs(userID string) ([]models.URLResponse, error)
+	UserStore
+	APIKeyStore
+	WorkspaceStore
+	AdminStore
+	AuditStore
+	TrashStore
+	DeleteOperationStore
+	LeaderStore
+	ExportStore
+	IdempotencyStore
+	RedirectStore
+	GetStats() (models.Stats, error)
 	Ping() error
 	Close() error
 }
 
+type DeleteOperationStore interface {
+	CreateDeleteOperation(operation models.DeleteOperation) error
+	GetDeleteOperation(operationID string) (models.DeleteOperation, error)
+	SetDeleteResults(operationID string, results []models.DeleteResult) error
+}
+
+// DeleteQueue durable background delete of links
+type DeleteQueue interface {
+	// persist deletes, links are deleted in background after return
+	Enqueue(ctx context.Context, URLs []models.DeleteURL) error
+}
+
 type Service struct {
-	logger   *logger.Logger
-	store    Store
-	deleteCh chan<- models.DeleteURL
+	logger  *logger.Logger
+	store   Store
+	deletes DeleteQueue
+	// election of instance which runs scheduled jobs
+	leadership Leadership
+	// logins of registered users allowed to use admin api
+	admins map[string]struct{}
+	// window in which responses are replayed by idempotency key, zero disables keys
+	idempotencyTTL time.Duration
 }
 
 // build service
-func NewService(logger *logger.Logger, store Store, deleteCh chan models.DeleteURL) *Service {
+func NewService(logger *logger.Logger, store Store, deletes DeleteQueue, leadership Leadership, adminLogins []string,
+	idempotencyTTL time.Duration) *Service {
+	admins := make(map[string]struct{}, len(adminLogins))
+	for _, login := range adminLogins {
+		admins[login] = struct{}{}
+	}
 	return &Service{
-		logger:   logger,
-		store:    store,
-		deleteCh: deleteCh,
+		logger:     logger,
+		store:      store,
+		deletes:    deletes,
+		leadership: leadership,
+		admins:     admins,
+
+		idempotencyTTL: idempotencyTTL,
 	}
 }
 
-// CreateShortURL get original url and return short link
-func (s *Service) CreateShortURL(userID string, originalURL string) (string, error) {
+// CreateShortURL get original url and return short link, title and policy are applied only to new link
+func (s *Service) CreateShortURL(ctx context.Context, actor models.Actor, originalURL, title string, policy models.RedirectPolicy) (string, error) {
 	s.logger.Info("CreateShortURL take", zap.String("original", originalURL))
+	if err := ValidateRedirectPolicy(policy); err != nil {
+		return "", err
+	}
+	if err := validateTitle(title); err != nil {
+		return "", err
+	}
+	now := time.Now().UTC()
 	dataURL := models.ShortenerURL{
-		UserID:      userID,
-		OriginalURL: originalURL,
-		ShortURL:    generateShortLink(),
+		UserID:         actor.UserID,
+		OriginalURL:    originalURL,
+		ShortURL:       generateShortLink(),
+		Title:          title,
+		CreatedAt:      &now,
+		RedirectPolicy: policy,
 	}
 
 	//database logic
-	existsShort, err := s.store.SaveShortURL(dataURL)
+	existsShort, err := s.store.SaveShortURL(ctx, dataURL)
 	if err == nil {
 		s.logger.Info("CreateShortURL return", zap.String("short", dataURL.ShortURL))
+		s.audit(actor, models.AuditLinkCreate, dataURL.ShortURL, "", originalURL, "")
 		return dataURL.ShortURL, nil
 	} else if errors.Is(err, store.ErrShortExists) {
 		return existsShort, ErrShortExists
@@ -63,46 +116,41 @@ func (s *Service) CreateShortURL(userID string, originalURL string) (string, err
 
 }
 
-// CreateShortURLs get original urls and return short links
-func (s *Service) CreateShortURLs(userID string, originalURLs []string) ([]string, error) {
+// CreateShortURLs get original urls and return links in same order, originals which already had link are returned with existing short and conflict flag
+func (s *Service) CreateShortURLs(ctx context.Context, actor models.Actor, originalURLs []string) ([]models.ShortenerURL, error) {
 	s.logger.Info("CreateShortURLs take", zap.Any("original", originalURLs))
 	URLs := make([]models.ShortenerURL, len(originalURLs))
 	for i, v := range originalURLs {
-		URLs[i] = models.ShortenerURL{
-			UserID:      userID,
-			OriginalURL: v,
-			ShortURL:    generateShortLink(),
+		URLs[i] = models.ShortenerURL{OriginalURL: v}
+	}
+	return s.createShortURLs(ctx, actor, URLs)
+}
+
+// save links of actor with new shorts, links without creation time are created now
+func (s *Service) createShortURLs(ctx context.Context, actor models.Actor, URLs []models.ShortenerURL) ([]models.ShortenerURL, error) {
+	now := time.Now().UTC()
+	for i := range URLs {
+		URLs[i].UserID = actor.UserID
+		URLs[i].ShortURL = generateShortLink()
+		if URLs[i].CreatedAt == nil {
+			URLs[i].CreatedAt = &now
 		}
 	}
 
 	// store logic
-	respURLs, err := s.store.SaveShortURLs(URLs)
+	respURLs, err := s.store.SaveShortURLs(ctx, URLs)
 	if err != nil {
 		return nil, fmt.Errorf("SaveShortURLs store err: %w", err)
 	}
-
-	// return short URLs
-	shortURLs := make([]string, len(respURLs))
-	for i, v := range respURLs {
-		shortURLs[i] = v.ShortURL
-	}
-	s.logger.Info("CreateShortURLs return", zap.Any("short", shortURLs))
-	return shortURLs, nil
-}
-
-func (s *Service) GetOriginalURLService(shortLink string) (string, error) {
-	s.logger.Info("GetOriginalURLService", zap.String("short", shortLink))
-	originalURL, err := s.store.GetOriginalURL(shortLink)
-	if err != nil && errors.Is(err, store.ErrURLDeleted) {
-		s.logger.Info("GetOriginalURL", zap.Error(err))
-		return "", ErrURLDeleted
-	} else if err != nil {
-		s.logger.Info("GetOriginalURL", zap.Error(err))
-		return "", fmt.Errorf("GetOriginalURL store err: %w", err)
+	for _, URL := range respURLs {
+		if URL.Conflict {
+			continue
+		}
+		s.audit(actor, models.AuditLinkBatchCreate, URL.ShortURL, "", URL.OriginalURL, "")
 	}
 
-	s.logger.Info("GetOriginalURLService", zap.String("original", originalURL))
-	return originalURL, nil
+	s.logger.Info("CreateShortURLs return", zap.Int("count", len(respURLs)))
+	return respURLs, nil
 }
 
 func (s *Service) GetUserURLsService(userID string) ([]models.URLResponse, error) {
@@ -118,13 +166,111 @@ func (s *Service) GetUserURLsService(userID string) ([]models.URLResponse, error
 	return userURLs, nil
 }
 
-func (s *Service) DeleteUserURLs(userID string, URLs []string) {
-	for _, v := range URLs {
-		s.deleteCh <- models.DeleteURL{
-			UserID:   userID,
-			ShortURL: v,
+// DeleteUserURLs check links of user and queue own links for delete, result of every link is tracked by
+// returned operation.
+func (s *Service) DeleteUserURLs(actor models.Actor, shortURLs []string) (models.DeleteOperation, error) {
+	s.logger.Info("DeleteUserURLs", zap.String("user id", actor.UserID), zap.Strings("short", shortURLs))
+	now := time.Now().UTC()
+	operation := models.DeleteOperation{
+		ID:        generateSecret(lenOperationID),
+		UserID:    actor.UserID,
+		Results:   make([]models.DeleteResult, 0, len(shortURLs)),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	// links are loaded by one query and classified in order of request
+	found, err := s.store.GetURLs(shortURLs)
+	if err != nil {
+		return models.DeleteOperation{}, fmt.Errorf("GetURLs store error: %w", err)
+	}
+	URLs := make(map[string]models.AdminURL, len(found))
+	for _, URL := range found {
+		URLs[URL.ShortURL] = URL
+	}
+
+	// links which will be deleted, kept for audit
+	toDelete := make([]models.AdminURL, 0, len(shortURLs))
+	seen := make(map[string]struct{}, len(shortURLs))
+	for _, short := range shortURLs {
+		if _, ok := seen[short]; ok {
+			continue
 		}
+		seen[short] = struct{}{}
+
+		result := models.DeleteResult{ShortURL: short, Status: models.DeleteResultPending}
+		URL, ok := URLs[short]
+		switch {
+		case !ok:
+			result.Status = models.DeleteResultNotFound
+		// workspace links are deleted through workspace by role of member
+		case URL.UserID != actor.UserID, len(URL.WorkspaceID) != 0:
+			result.Status = models.DeleteResultNotOwned
+		case URL.Deleted:
+			result.Status = models.DeleteResultDeleted
+		default:
+			toDelete = append(toDelete, URL)
+		}
+		operation.Results = append(operation.Results, result)
+	}
+	operation.Status = models.OperationStatus(operation.Results)
+
+	if err := s.store.CreateDeleteOperation(operation); err != nil {
+		return models.DeleteOperation{}, fmt.Errorf("CreateDeleteOperation store error: %w", err)
+	}
+	if len(toDelete) == 0 {
+		return operation, nil
+	}
+
+	// deletes are persisted before accept, so they survive restart
+	deletes := make([]models.DeleteURL, len(toDelete))
+	for i, URL := range toDelete {
+		deletes[i] = models.DeleteURL{UserID: actor.UserID, ShortURL: URL.ShortURL, OperationID: operation.ID}
+	}
+	if err := s.deletes.Enqueue(context.Background(), deletes); err != nil {
+		s.failDeletes(operation.ID, toDelete, err)
+		return models.DeleteOperation{}, fmt.Errorf("Enqueue deletes error: %w", err)
+	}
+	for _, URL := range toDelete {
+		s.audit(actor, models.AuditLinkDelete, URL.ShortURL, URL.OriginalURL, "", "")
+	}
+	return operation, nil
+}
+
+// mark links of operation failed when their deletes are not queued
+func (s *Service) failDeletes(operationID string, URLs []models.AdminURL, cause error) {
+	results := make([]models.DeleteResult, len(URLs))
+	for i, URL := range URLs {
+		results[i] = models.DeleteResult{ShortURL: URL.ShortURL, Status: models.DeleteResultFailed, Error: cause.Error()}
+	}
+	if err := s.store.SetDeleteResults(operationID, results); err != nil {
+		s.logger.Info("SetDeleteResults", zap.String("operation id", operationID), zap.Error(err))
+	}
+}
+
+// GetUserOperation return delete operation of user with result of every link.
+func (s *Service) GetUserOperation(userID, operationID string) (models.DeleteOperation, error) {
+	operation, err := s.store.GetDeleteOperation(operationID)
+	if err != nil && errors.Is(err, store.ErrOperationNotExists) {
+		return models.DeleteOperation{}, ErrOperationNotExists
+	} else if err != nil {
+		return models.DeleteOperation{}, fmt.Errorf("GetDeleteOperation store error: %w", err)
+	}
+	// operations of other users are hidden
+	if operation.UserID != userID {
+		return models.DeleteOperation{}, ErrOperationNotExists
+	}
+	operation.Status = models.OperationStatus(operation.Results)
+	return operation, nil
+}
+
+// GetStats return count of URLs, their owners and deleted URLs.
+func (s *Service) GetStats() (models.Stats, error) {
+	stats, err := s.store.GetStats()
+	if err != nil {
+		return models.Stats{}, fmt.Errorf("GetStats store error: %w", err)
 	}
+	return stats, nil
 }
 
 func (s *Service) Ping() error {
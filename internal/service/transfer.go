@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+
+	"github.com/hollgett/shortener.git/internal/models"
+)
+
+// count of links read from store at once on export
+const exportPageSize = 500
+
+var ErrImportTooLarge = errors.New("too many rows in import")
+
+// max count of rows in one import
+const maxImportRows = 10000
+
+type ExportStore interface {
+	ScanURLs(userID, afterShort string, limit int) ([]models.ShortenerURL, error)
+}
+
+// ExportUserURLs read all links of user including deleted ones by pages ordered by short and pass every page to write.
+func (s *Service) ExportUserURLs(userID string, write func(URLs []models.ShortenerURL) error) error {
+	after := ""
+	for {
+		URLs, err := s.store.ScanURLs(userID, after, exportPageSize)
+		if err != nil {
+			return fmt.Errorf("ScanURLs store error: %w", err)
+		}
+		if len(URLs) == 0 {
+			return nil
+		}
+		if err := write(URLs); err != nil {
+			return err
+		}
+		if len(URLs) < exportPageSize {
+			return nil
+		}
+		after = URLs[len(URLs)-1].ShortURL
+	}
+}
+
+// ImportUserURLs validate rows and create links of valid ones, return result of every row in same order.
+func (s *Service) ImportUserURLs(ctx context.Context, actor models.Actor, rows []models.ImportRow) (models.ImportReport, error) {
+	if len(rows) > maxImportRows {
+		return models.ImportReport{}, fmt.Errorf("%w: %d, max %d", ErrImportTooLarge, len(rows), maxImportRows)
+	}
+
+	report := models.ImportReport{Rows: make([]models.ImportResult, len(rows))}
+	URLs := make([]models.ShortenerURL, 0, len(rows))
+	// index in report of every link passed to createShortURLs
+	valid := make([]int, 0, len(rows))
+	for i, row := range rows {
+		result := models.ImportResult{Row: row.Row, OriginalURL: row.OriginalURL}
+		switch err := validateImportRow(row); {
+		case err != nil:
+			result.Status, result.Error = models.ImportInvalid, err.Error()
+			report.Invalid++
+		case row.Deleted:
+			result.Status = models.ImportSkipped
+			report.Skipped++
+		default:
+			URLs = append(URLs, models.ShortenerURL{
+				OriginalURL:    row.OriginalURL,
+				Title:          row.Title,
+				CreatedAt:      row.CreatedAt,
+				RedirectPolicy: row.RedirectPolicy,
+			})
+			valid = append(valid, i)
+		}
+		report.Rows[i] = result
+	}
+	if len(URLs) == 0 {
+		return report, nil
+	}
+
+	URLs, err := s.createShortURLs(ctx, actor, URLs)
+	if err != nil {
+		return models.ImportReport{}, err
+	}
+	for i, URL := range URLs {
+		result := &report.Rows[valid[i]]
+		result.ShortURL = URL.ShortURL
+		if URL.Conflict {
+			result.Status = models.ImportExists
+			report.Exists++
+		} else {
+			result.Status = models.ImportCreated
+			report.Created++
+		}
+	}
+	return report, nil
+}
+
+func validateImportRow(row models.ImportRow) error {
+	if row.Error != "" {
+		return errors.New(row.Error)
+	}
+	if row.OriginalURL == "" {
+		return errors.New("original_url is empty")
+	}
+	parsed, err := url.ParseRequestURI(row.OriginalURL)
+	if err != nil {
+		return fmt.Errorf("invalid original_url: %w", err)
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" || parsed.Host == "" {
+		return errors.New("original_url must be absolute http or https URL")
+	}
+	if err := ValidateRedirectPolicy(row.RedirectPolicy); err != nil {
+		return err
+	}
+	return validateTitle(row.Title)
+}
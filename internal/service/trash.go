@@ -0,0 +1,51 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hollgett/shortener.git/internal/models"
+	"go.uber.org/zap"
+)
+
+type TrashStore interface {
+	GetDeletedUserURLs(userID string) ([]models.DeletedURLResponse, error)
+	RestoreURLs(userID string, shortURLs []string) ([]string, error)
+	PurgeDeletedURLs(deletedBefore time.Time) (int, error)
+}
+
+// GetUserTrash return deleted links of user, newest first.
+func (s *Service) GetUserTrash(userID string) ([]models.DeletedURLResponse, error) {
+	URLs, err := s.store.GetDeletedUserURLs(userID)
+	if err != nil {
+		s.logger.Info("GetDeletedUserURLs", zap.Error(err))
+		return nil, fmt.Errorf("GetDeletedUserURLs store error: %w", err)
+	}
+	return URLs, nil
+}
+
+// RestoreUserURLs undelete own links of user and return restored short links,
+// links of other users, not deleted or purged are skipped.
+func (s *Service) RestoreUserURLs(actor models.Actor, shortURLs []string) ([]string, error) {
+	restored, err := s.store.RestoreURLs(actor.UserID, shortURLs)
+	if err != nil {
+		return nil, fmt.Errorf("RestoreURLs store error: %w", err)
+	}
+	for _, short := range restored {
+		URL, err := s.store.GetURL(short)
+		if err != nil {
+			return nil, fmt.Errorf("GetURL store error: %w", err)
+		}
+		s.audit(actor, models.AuditLinkRestore, short, "", URL.OriginalURL, "")
+	}
+	return restored, nil
+}
+
+// PurgeDeletedURLs hard delete links deleted earlier than retention ago, return count of purged links.
+func (s *Service) PurgeDeletedURLs(retention time.Duration) (int, error) {
+	purged, err := s.store.PurgeDeletedURLs(time.Now().Add(-retention))
+	if err != nil {
+		return 0, fmt.Errorf("PurgeDeletedURLs store error: %w", err)
+	}
+	return purged, nil
+}
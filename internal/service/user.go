@@ -0,0 +1,169 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hollgett/shortener.git/internal/models"
+	"github.com/hollgett/shortener.git/internal/store"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	minLenPassword = 8
+	maxLenPassword = 72 // bcrypt limit
+	maxLenLogin    = 255
+)
+
+var (
+	ErrUserExists         = errors.New("user with login exists")
+	ErrAlreadyRegistered  = errors.New("user already registered, logout first")
+	ErrInvalidCredentials = errors.New("invalid login or password")
+	ErrInvalidAuthData    = errors.New("invalid login or password format")
+)
+
+type UserStore interface {
+	CreateUser(user models.User) error
+	GetUserByLogin(login string) (models.User, error)
+	GetUserByID(userID string) (models.User, error)
+	ReassignUserURLs(fromUserID, toUserID string) error
+	CreateSession(session models.Session) error
+	GetSession(sessionID string) (models.Session, error)
+	RevokeSession(sessionID string) error
+	RevokeUserSessions(userID string) error
+	CreateUserIdentity(identity models.UserIdentity) error
+	GetUserIdentity(issuer, subject string) (models.UserIdentity, error)
+}
+
+// RegisterUser create account for current anonymous user, so his URLs stay with account, and open session.
+func (s *Service) RegisterUser(anonymousID, login, password string) (models.User, models.Session, error) {
+	s.logger.Info("RegisterUser", zap.String("login", login))
+	login = strings.TrimSpace(login)
+	if err := validateCredentials(login, password); err != nil {
+		return models.User{}, models.Session{}, err
+	}
+
+	if _, err := s.store.GetUserByID(anonymousID); err == nil {
+		return models.User{}, models.Session{}, ErrAlreadyRegistered
+	} else if !errors.Is(err, store.ErrUserNotExists) {
+		return models.User{}, models.Session{}, fmt.Errorf("GetUserByID store error: %w", err)
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return models.User{}, models.Session{}, fmt.Errorf("failed hash password: %w", err)
+	}
+	user := models.User{
+		ID:           anonymousID,
+		Login:        login,
+		PasswordHash: string(hash),
+		CreatedAt:    time.Now().UTC(),
+	}
+	if err := s.store.CreateUser(user); err != nil && errors.Is(err, store.ErrUserExists) {
+		return models.User{}, models.Session{}, ErrUserExists
+	} else if err != nil {
+		return models.User{}, models.Session{}, fmt.Errorf("CreateUser store error: %w", err)
+	}
+
+	session, err := s.openSession(user.ID)
+	if err != nil {
+		return models.User{}, models.Session{}, err
+	}
+	return user, session, nil
+}
+
+// LoginUser check password and open session.
+//
+// if anonymousID is not empty and doesn't belong to another account, URLs of anonymous user moved to account.
+func (s *Service) LoginUser(anonymousID, login, password string) (models.User, models.Session, error) {
+	s.logger.Info("LoginUser", zap.String("login", login))
+	user, err := s.store.GetUserByLogin(strings.TrimSpace(login))
+	if err != nil && errors.Is(err, store.ErrUserNotExists) {
+		return models.User{}, models.Session{}, ErrInvalidCredentials
+	} else if err != nil {
+		return models.User{}, models.Session{}, fmt.Errorf("GetUserByLogin store error: %w", err)
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
+		return models.User{}, models.Session{}, ErrInvalidCredentials
+	}
+
+	if err := s.claimAnonymousURLs(anonymousID, user.ID); err != nil {
+		return models.User{}, models.Session{}, err
+	}
+
+	session, err := s.openSession(user.ID)
+	if err != nil {
+		return models.User{}, models.Session{}, err
+	}
+	return user, session, nil
+}
+
+// move URLs of anonymous user to account, identities of other accounts are never merged
+func (s *Service) claimAnonymousURLs(anonymousID, userID string) error {
+	if len(anonymousID) == 0 || anonymousID == userID {
+		return nil
+	}
+	if _, err := s.store.GetUserByID(anonymousID); err == nil {
+		return nil
+	} else if !errors.Is(err, store.ErrUserNotExists) {
+		return fmt.Errorf("GetUserByID store error: %w", err)
+	}
+	if err := s.store.ReassignUserURLs(anonymousID, userID); err != nil {
+		return fmt.Errorf("ReassignUserURLs store error: %w", err)
+	}
+	s.logger.Info("claim anonymous URLs", zap.String("from", anonymousID), zap.String("to", userID))
+	return nil
+}
+
+func (s *Service) openSession(userID string) (models.Session, error) {
+	session := models.Session{
+		ID:        generateSessionID(),
+		UserID:    userID,
+		CreatedAt: time.Now().UTC(),
+	}
+	if err := s.store.CreateSession(session); err != nil {
+		return models.Session{}, fmt.Errorf("CreateSession store error: %w", err)
+	}
+	return session, nil
+}
+
+// LogoutUser revoke session, or all sessions of user if all is true
+func (s *Service) LogoutUser(userID, sessionID string, all bool) error {
+	if all {
+		if err := s.store.RevokeUserSessions(userID); err != nil {
+			return fmt.Errorf("RevokeUserSessions store error: %w", err)
+		}
+		return nil
+	}
+	if err := s.store.RevokeSession(sessionID); err != nil && !errors.Is(err, store.ErrSessionNotExists) {
+		return fmt.Errorf("RevokeSession store error: %w", err)
+	}
+	return nil
+}
+
+// IsSessionActive return false if session revoked or doesn't exist
+func (s *Service) IsSessionActive(sessionID string) (bool, error) {
+	session, err := s.store.GetSession(sessionID)
+	if err != nil && errors.Is(err, store.ErrSessionNotExists) {
+		return false, nil
+	} else if err != nil {
+		return false, fmt.Errorf("GetSession store error: %w", err)
+	}
+	return !session.Revoked, nil
+}
+
+func validateCredentials(login, password string) error {
+	if len(login) == 0 || len(login) > maxLenLogin {
+		return fmt.Errorf("%w: login length must be from 1 to %d", ErrInvalidAuthData, maxLenLogin)
+	}
+	if strings.HasPrefix(login, externalLoginPrefix) {
+		return fmt.Errorf("%w: login prefix %q is reserved", ErrInvalidAuthData, externalLoginPrefix)
+	}
+	if len(password) < minLenPassword || len(password) > maxLenPassword {
+		return fmt.Errorf("%w: password length must be from %d to %d", ErrInvalidAuthData, minLenPassword, maxLenPassword)
+	}
+	return nil
+}
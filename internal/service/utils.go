@@ -1,12 +1,16 @@
 package service
 
 import (
+	cryptorand "crypto/rand"
+	"encoding/hex"
 	"math/rand"
 	"time"
 )
 
 const (
-	lenShortLink int = 8
+	lenShortLink   int = 8
+	lenSessionID   int = 16
+	lenOperationID int = 16
 )
 
 var pseudoRand = rand.New(rand.NewSource(time.Now().Unix()))
@@ -25,3 +29,17 @@ func generateShortLink() string {
 	}
 	return string(shortLink)
 }
+
+// create session id with crypto random, hex encoded
+func generateSessionID() string {
+	return generateSecret(lenSessionID)
+}
+
+// create hex encoded crypto random string from n bytes
+func generateSecret(n int) string {
+	b := make([]byte, n)
+	if _, err := cryptorand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
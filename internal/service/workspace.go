@@ -0,0 +1,309 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hollgett/shortener.git/internal/models"
+	"github.com/hollgett/shortener.git/internal/store"
+	"go.uber.org/zap"
+)
+
+const (
+	lenWorkspaceID      = 8
+	maxLenWorkspaceName = 255
+)
+
+var (
+	ErrWorkspaceNotExists    = errors.New("workspace doesn't exist")
+	ErrWorkspaceForbidden    = errors.New("workspace role doesn't allow operation")
+	ErrInvalidWorkspaceData  = errors.New("invalid workspace data")
+	ErrMemberNotExists       = errors.New("workspace member doesn't exist")
+	ErrLastWorkspaceOwner    = errors.New("workspace must keep at least one owner")
+	ErrWorkspaceURLNotExists = errors.New("url doesn't exist in workspace")
+)
+
+// rank of roles, higher role allows everything of lower
+var roleRank = map[string]int{
+	models.RoleViewer: 1,
+	models.RoleEditor: 2,
+	models.RoleOwner:  3,
+}
+
+type WorkspaceStore interface {
+	CreateWorkspace(workspace models.Workspace, owner models.WorkspaceMember) error
+	GetWorkspace(workspaceID string) (models.Workspace, error)
+	GetUserWorkspaces(userID string) ([]models.WorkspaceResponse, error)
+	GetWorkspaceMember(workspaceID, userID string) (models.WorkspaceMember, error)
+	GetWorkspaceMembers(workspaceID string) ([]models.WorkspaceMember, error)
+	SaveWorkspaceMember(member models.WorkspaceMember) error
+	DeleteWorkspaceMember(workspaceID, userID string) error
+	GetWorkspaceURLs(workspaceID string) ([]models.URLResponse, error)
+	MoveURLToWorkspace(userID, shortURL, workspaceID string) error
+	UpdateWorkspaceURL(workspaceID, shortURL, originalURL string) error
+	DeleteWorkspaceURLs(workspaceID string, shortURLs []string) error
+}
+
+// CreateWorkspace create workspace, creator become its owner.
+func (s *Service) CreateWorkspace(userID, name string) (models.WorkspaceResponse, error) {
+	s.logger.Info("CreateWorkspace", zap.String("user id", userID), zap.String("name", name))
+	name = strings.TrimSpace(name)
+	if len(name) == 0 || len(name) > maxLenWorkspaceName {
+		return models.WorkspaceResponse{}, ErrInvalidWorkspaceData
+	}
+
+	now := time.Now().UTC()
+	workspace := models.Workspace{
+		ID:        generateSecret(lenWorkspaceID),
+		Name:      name,
+		CreatedAt: now,
+	}
+	owner := models.WorkspaceMember{
+		WorkspaceID: workspace.ID,
+		UserID:      userID,
+		Role:        models.RoleOwner,
+		CreatedAt:   now,
+	}
+	if err := s.store.CreateWorkspace(workspace, owner); err != nil {
+		return models.WorkspaceResponse{}, fmt.Errorf("CreateWorkspace store error: %w", err)
+	}
+
+	return models.WorkspaceResponse{
+		ID:        workspace.ID,
+		Name:      workspace.Name,
+		Role:      owner.Role,
+		CreatedAt: workspace.CreatedAt,
+	}, nil
+}
+
+func (s *Service) GetUserWorkspaces(userID string) ([]models.WorkspaceResponse, error) {
+	workspaces, err := s.store.GetUserWorkspaces(userID)
+	if err != nil {
+		return nil, fmt.Errorf("GetUserWorkspaces store error: %w", err)
+	}
+	return workspaces, nil
+}
+
+// GetWorkspaceMembers return members of workspace, any member can see them.
+func (s *Service) GetWorkspaceMembers(userID, workspaceID string) ([]models.WorkspaceMember, error) {
+	if err := s.requireRole(userID, workspaceID, models.RoleViewer); err != nil {
+		return nil, err
+	}
+	members, err := s.store.GetWorkspaceMembers(workspaceID)
+	if err != nil {
+		return nil, fmt.Errorf("GetWorkspaceMembers store error: %w", err)
+	}
+	return members, nil
+}
+
+// SaveWorkspaceMember add registered user to workspace or change role of member, only owner can do it.
+func (s *Service) SaveWorkspaceMember(userID, workspaceID, memberID, role string) (models.WorkspaceMember, error) {
+	s.logger.Info("SaveWorkspaceMember", zap.String("workspace", workspaceID), zap.String("member", memberID), zap.String("role", role))
+	if _, ok := roleRank[role]; !ok || len(memberID) == 0 {
+		return models.WorkspaceMember{}, ErrInvalidWorkspaceData
+	}
+	if err := s.requireRole(userID, workspaceID, models.RoleOwner); err != nil {
+		return models.WorkspaceMember{}, err
+	}
+	if _, err := s.store.GetUserByID(memberID); err != nil && errors.Is(err, store.ErrUserNotExists) {
+		return models.WorkspaceMember{}, fmt.Errorf("%w: user isn't registered", ErrInvalidWorkspaceData)
+	} else if err != nil {
+		return models.WorkspaceMember{}, fmt.Errorf("GetUserByID store error: %w", err)
+	}
+	if role != models.RoleOwner {
+		if err := s.keepOwner(workspaceID, memberID); err != nil {
+			return models.WorkspaceMember{}, err
+		}
+	}
+
+	member := models.WorkspaceMember{
+		WorkspaceID: workspaceID,
+		UserID:      memberID,
+		Role:        role,
+		CreatedAt:   time.Now().UTC(),
+	}
+	if err := s.store.SaveWorkspaceMember(member); err != nil {
+		return models.WorkspaceMember{}, fmt.Errorf("SaveWorkspaceMember store error: %w", err)
+	}
+	return member, nil
+}
+
+// DeleteWorkspaceMember remove member from workspace, owner can remove anyone, member can leave himself.
+func (s *Service) DeleteWorkspaceMember(userID, workspaceID, memberID string) error {
+	s.logger.Info("DeleteWorkspaceMember", zap.String("workspace", workspaceID), zap.String("member", memberID))
+	minRole := models.RoleOwner
+	if userID == memberID {
+		minRole = models.RoleViewer
+	}
+	if err := s.requireRole(userID, workspaceID, minRole); err != nil {
+		return err
+	}
+	if err := s.keepOwner(workspaceID, memberID); err != nil {
+		return err
+	}
+
+	err := s.store.DeleteWorkspaceMember(workspaceID, memberID)
+	if err != nil && errors.Is(err, store.ErrMemberNotExists) {
+		return ErrMemberNotExists
+	} else if err != nil {
+		return fmt.Errorf("DeleteWorkspaceMember store error: %w", err)
+	}
+	return nil
+}
+
+// GetWorkspaceURLs return links of workspace, any member can see them.
+func (s *Service) GetWorkspaceURLs(userID, workspaceID string) ([]models.URLResponse, error) {
+	if err := s.requireRole(userID, workspaceID, models.RoleViewer); err != nil {
+		return nil, err
+	}
+	URLs, err := s.store.GetWorkspaceURLs(workspaceID)
+	if err != nil {
+		return nil, fmt.Errorf("GetWorkspaceURLs store error: %w", err)
+	}
+	return URLs, nil
+}
+
+// CreateWorkspaceURL create short link owned by workspace, creator is kept as author.
+func (s *Service) CreateWorkspaceURL(ctx context.Context, actor models.Actor, workspaceID, originalURL string) (string, error) {
+	s.logger.Info("CreateWorkspaceURL", zap.String("workspace", workspaceID), zap.String("original", originalURL))
+	if len(originalURL) == 0 {
+		return "", ErrInvalidWorkspaceData
+	}
+	if err := s.requireRole(actor.UserID, workspaceID, models.RoleEditor); err != nil {
+		return "", err
+	}
+
+	dataURL := models.ShortenerURL{
+		UserID:      actor.UserID,
+		WorkspaceID: workspaceID,
+		OriginalURL: originalURL,
+		ShortURL:    generateShortLink(),
+	}
+	existsShort, err := s.store.SaveShortURL(ctx, dataURL)
+	if err != nil && errors.Is(err, store.ErrShortExists) {
+		return existsShort, ErrShortExists
+	} else if err != nil {
+		return "", fmt.Errorf("SaveShortURL store error: %w", err)
+	}
+	s.audit(actor, models.AuditLinkCreate, dataURL.ShortURL, "", originalURL, "workspace "+workspaceID)
+	return dataURL.ShortURL, nil
+}
+
+// MoveURLToWorkspace transfer own personal link to workspace.
+func (s *Service) MoveURLToWorkspace(actor models.Actor, workspaceID, shortURL string) error {
+	s.logger.Info("MoveURLToWorkspace", zap.String("workspace", workspaceID), zap.String("short", shortURL))
+	if err := s.requireRole(actor.UserID, workspaceID, models.RoleEditor); err != nil {
+		return err
+	}
+	URL, err := s.store.GetURL(shortURL)
+	if err != nil && errors.Is(err, store.ErrIsNotExists) {
+		return ErrUserURLsNotExists
+	} else if err != nil {
+		return fmt.Errorf("GetURL store error: %w", err)
+	}
+
+	err = s.store.MoveURLToWorkspace(actor.UserID, shortURL, workspaceID)
+	if err != nil && errors.Is(err, store.ErrIsNotExists) {
+		return ErrUserURLsNotExists
+	} else if err != nil {
+		return fmt.Errorf("MoveURLToWorkspace store error: %w", err)
+	}
+	s.audit(actor, models.AuditLinkUpdate, shortURL, URL.WorkspaceID, workspaceID, "workspace")
+	return nil
+}
+
+// UpdateWorkspaceURL change original url of workspace link.
+func (s *Service) UpdateWorkspaceURL(actor models.Actor, workspaceID, shortURL, originalURL string) error {
+	s.logger.Info("UpdateWorkspaceURL", zap.String("workspace", workspaceID), zap.String("short", shortURL))
+	if len(originalURL) == 0 {
+		return ErrInvalidWorkspaceData
+	}
+	if err := s.requireRole(actor.UserID, workspaceID, models.RoleEditor); err != nil {
+		return err
+	}
+	URL, err := s.store.GetURL(shortURL)
+	if err != nil && errors.Is(err, store.ErrIsNotExists) {
+		return ErrWorkspaceURLNotExists
+	} else if err != nil {
+		return fmt.Errorf("GetURL store error: %w", err)
+	}
+
+	err = s.store.UpdateWorkspaceURL(workspaceID, shortURL, originalURL)
+	switch {
+	case err == nil:
+		s.audit(actor, models.AuditLinkUpdate, shortURL, URL.OriginalURL, originalURL, "")
+		return nil
+	case errors.Is(err, store.ErrIsNotExists):
+		return ErrWorkspaceURLNotExists
+	case errors.Is(err, store.ErrShortExists):
+		return ErrShortExists
+	default:
+		return fmt.Errorf("UpdateWorkspaceURL store error: %w", err)
+	}
+}
+
+// DeleteWorkspaceURLs mark workspace links deleted, links of other workspaces are skipped.
+func (s *Service) DeleteWorkspaceURLs(actor models.Actor, workspaceID string, shortURLs []string) error {
+	s.logger.Info("DeleteWorkspaceURLs", zap.String("workspace", workspaceID), zap.Strings("short", shortURLs))
+	if err := s.requireRole(actor.UserID, workspaceID, models.RoleEditor); err != nil {
+		return err
+	}
+
+	// links which will be deleted, kept for audit
+	deleted := make([]models.AdminURL, 0, len(shortURLs))
+	for _, short := range shortURLs {
+		URL, err := s.store.GetURL(short)
+		if err != nil && errors.Is(err, store.ErrIsNotExists) {
+			continue
+		} else if err != nil {
+			return fmt.Errorf("GetURL store error: %w", err)
+		}
+		if URL.WorkspaceID == workspaceID && !URL.Deleted {
+			deleted = append(deleted, URL)
+		}
+	}
+
+	if err := s.store.DeleteWorkspaceURLs(workspaceID, shortURLs); err != nil {
+		return fmt.Errorf("DeleteWorkspaceURLs store error: %w", err)
+	}
+	for _, URL := range deleted {
+		s.audit(actor, models.AuditLinkDelete, URL.ShortURL, URL.OriginalURL, "", "workspace "+workspaceID)
+	}
+	return nil
+}
+
+// check role of user in workspace, not member get ErrWorkspaceNotExists so workspace existence isn't leaked
+func (s *Service) requireRole(userID, workspaceID, minRole string) error {
+	member, err := s.store.GetWorkspaceMember(workspaceID, userID)
+	if err != nil && errors.Is(err, store.ErrMemberNotExists) {
+		return ErrWorkspaceNotExists
+	} else if err != nil {
+		return fmt.Errorf("GetWorkspaceMember store error: %w", err)
+	}
+	if roleRank[member.Role] < roleRank[minRole] {
+		return ErrWorkspaceForbidden
+	}
+	return nil
+}
+
+// check that workspace keeps owner after member lose owner role
+func (s *Service) keepOwner(workspaceID, memberID string) error {
+	members, err := s.store.GetWorkspaceMembers(workspaceID)
+	if err != nil {
+		return fmt.Errorf("GetWorkspaceMembers store error: %w", err)
+	}
+	for _, member := range members {
+		if member.Role == models.RoleOwner && member.UserID != memberID {
+			return nil
+		}
+	}
+	for _, member := range members {
+		if member.UserID == memberID && member.Role == models.RoleOwner {
+			return ErrLastWorkspaceOwner
+		}
+	}
+	return nil
+}
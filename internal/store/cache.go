@@ -0,0 +1,240 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"github.com/hollgett/shortener.git/internal/logger"
+	"github.com/hollgett/shortener.git/internal/models"
+	"go.uber.org/zap"
+)
+
+// delay before listen of invalidations again after error
+const timeRelisten = time.Second
+
+// CacheInvalidator deliver invalidation of cached links to all instances
+type CacheInvalidator interface {
+	PublishInvalidate(shortURLs []string) error
+	// block until context is done or connection is lost, ready is called when listen is started
+	ListenInvalidate(ctx context.Context, ready func(), invalidate func(shortURLs []string)) error
+}
+
+type CacheConfig struct {
+	// max count of cached links
+	Size int
+	TTL  time.Duration
+	// lifetime of cached missing links
+	NegativeTTL time.Duration
+}
+
+// CachedStore is read-through cache of redirects in front of store, links are invalidated on change
+// in this instance and by notifications of other instances if store is CacheInvalidator.
+type CachedStore struct {
+	Store
+	logger      *logger.Logger
+	cfg         CacheConfig
+	cache       *lruCache[cachedURL]
+	invalidator CacheInvalidator
+
+	hits         *atomic.Int64
+	negativeHits *atomic.Int64
+	misses       *atomic.Int64
+	evictions    *atomic.Int64
+}
+
+type cachedURL struct {
+	redirect models.Redirect
+	// link doesn't exist
+	missing bool
+}
+
+func NewCachedStore(logger *logger.Logger, store Store, cfg CacheConfig) *CachedStore {
+	c := &CachedStore{
+		Store:        store,
+		logger:       logger,
+		cfg:          cfg,
+		hits:         &atomic.Int64{},
+		negativeHits: &atomic.Int64{},
+		misses:       &atomic.Int64{},
+		evictions:    &atomic.Int64{},
+	}
+	c.cache = newLRUCache[cachedURL](cfg.Size, func() { c.evictions.Add(1) })
+	if invalidator, ok := store.(CacheInvalidator); ok {
+		c.invalidator = invalidator
+	}
+	return c
+}
+
+func (c *CachedStore) GetRedirect(ctx context.Context, shortURL string) (models.Redirect, error) {
+	if cached, ok := c.cache.get(shortURL); ok {
+		if cached.missing {
+			c.negativeHits.Add(1)
+			return models.Redirect{}, ErrIsNotExists
+		}
+		c.hits.Add(1)
+		return cached.redirect, nil
+	}
+	c.misses.Add(1)
+
+	redirect, err := c.Store.GetRedirect(ctx, shortURL)
+	switch {
+	case err == nil:
+		c.cache.set(shortURL, cachedURL{redirect: redirect}, c.cfg.TTL)
+	case errors.Is(err, ErrIsNotExists) && c.cfg.NegativeTTL > 0:
+		c.cache.set(shortURL, cachedURL{missing: true}, c.cfg.NegativeTTL)
+	}
+	return redirect, err
+}
+
+func (c *CachedStore) GetStats() (models.Stats, error) {
+	stats, err := c.Store.GetStats()
+	if err != nil {
+		return models.Stats{}, err
+	}
+	hits, negativeHits, misses := c.hits.Load(), c.negativeHits.Load(), c.misses.Load()
+	stats.Cache = &models.CacheStats{
+		Size:         c.cache.len(),
+		Hits:         hits,
+		NegativeHits: negativeHits,
+		Misses:       misses,
+		Evictions:    c.evictions.Load(),
+	}
+	if total := hits + negativeHits + misses; total != 0 {
+		stats.Cache.HitRatio = float64(hits+negativeHits) / float64(total)
+	}
+	return stats, nil
+}
+
+// new links can be cached as missing
+func (c *CachedStore) SaveShortURL(ctx context.Context, URL models.ShortenerURL) (string, error) {
+	short, err := c.Store.SaveShortURL(ctx, URL)
+	if err == nil {
+		c.invalidate(URL.ShortURL)
+	}
+	return short, err
+}
+
+func (c *CachedStore) SaveShortURLs(ctx context.Context, URLs []models.ShortenerURL) ([]models.ShortenerURL, error) {
+	saved, err := c.Store.SaveShortURLs(ctx, URLs)
+	if err == nil {
+		shortURLs := make([]string, 0, len(saved))
+		for _, URL := range saved {
+			if !URL.Conflict {
+				shortURLs = append(shortURLs, URL.ShortURL)
+			}
+		}
+		c.invalidate(shortURLs...)
+	}
+	return saved, err
+}
+
+func (c *CachedStore) DeleteURLs(URLs []models.DeleteURL) error {
+	err := c.Store.DeleteURLs(URLs)
+	shortURLs := make([]string, len(URLs))
+	for i, URL := range URLs {
+		shortURLs[i] = URL.ShortURL
+	}
+	c.invalidate(shortURLs...)
+	return err
+}
+
+func (c *CachedStore) RestoreURLs(userID string, shortURLs []string) ([]string, error) {
+	restored, err := c.Store.RestoreURLs(userID, shortURLs)
+	if err == nil {
+		c.invalidate(restored...)
+	}
+	return restored, err
+}
+
+func (c *CachedStore) UpdateWorkspaceURL(workspaceID, shortURL, originalURL string) error {
+	err := c.Store.UpdateWorkspaceURL(workspaceID, shortURL, originalURL)
+	c.invalidate(shortURL)
+	return err
+}
+
+func (c *CachedStore) DeleteWorkspaceURLs(workspaceID string, shortURLs []string) error {
+	err := c.Store.DeleteWorkspaceURLs(workspaceID, shortURLs)
+	c.invalidate(shortURLs...)
+	return err
+}
+
+func (c *CachedStore) SetURLDisabled(shortURL string, disabled bool) error {
+	err := c.Store.SetURLDisabled(shortURL, disabled)
+	c.invalidate(shortURL)
+	return err
+}
+
+func (c *CachedStore) SetURLRedirect(shortURL string, policy models.RedirectPolicy) error {
+	err := c.Store.SetURLRedirect(shortURL, policy)
+	c.invalidate(shortURL)
+	return err
+}
+
+func (c *CachedStore) SetURLTitle(shortURL, title string) error {
+	err := c.Store.SetURLTitle(shortURL, title)
+	c.invalidate(shortURL)
+	return err
+}
+
+func (c *CachedStore) HardDeleteURL(shortURL string) error {
+	err := c.Store.HardDeleteURL(shortURL)
+	c.invalidate(shortURL)
+	return err
+}
+
+// imported links can be cached as missing
+func (c *CachedStore) ImportURLs(URLs []models.ShortenerURL) (int, error) {
+	imported, err := c.Store.ImportURLs(URLs)
+	if imported > 0 {
+		shortURLs := make([]string, len(URLs))
+		for i, URL := range URLs {
+			shortURLs[i] = URL.ShortURL
+		}
+		c.invalidate(shortURLs...)
+	}
+	return imported, err
+}
+
+// remove links from cache of this instance and notify other instances, store is changed already
+func (c *CachedStore) invalidate(shortURLs ...string) {
+	if len(shortURLs) == 0 {
+		return
+	}
+	c.cache.remove(shortURLs...)
+	if c.invalidator == nil {
+		return
+	}
+	if err := c.invalidator.PublishInvalidate(shortURLs); err != nil {
+		c.logger.Info("publish cache invalidate", zap.Error(err))
+	}
+}
+
+func (c *CachedStore) Name() string {
+	return "cache invalidation listener"
+}
+
+// Run listen invalidations of other instances until context is done, cache is cleared every time listen is
+// started because notifications could be missed.
+func (c *CachedStore) Run(ctx context.Context) {
+	if c.invalidator == nil {
+		<-ctx.Done()
+		return
+	}
+	for {
+		err := c.invalidator.ListenInvalidate(ctx, c.cache.clear, func(shortURLs []string) {
+			c.cache.remove(shortURLs...)
+		})
+		if ctx.Err() != nil {
+			return
+		}
+		c.logger.Info("listen cache invalidate", zap.Error(err))
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(timeRelisten):
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package store
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/hollgett/shortener.git/internal/models"
+)
+
+// DedupScope in which same original gets existing short link instead of new one.
+//
+// keys of existing links are rebuilt on start when scope is changed, oldest link of key keeps it and others
+// are not deduplicated. Memory store builds keys on load, database rebuilds them in rekeyDedup.
+type DedupScope string
+
+const (
+	// original has single link for all users
+	DedupGlobal DedupScope = "global"
+	// original has single link per user
+	DedupUser DedupScope = "user"
+	// every request creates new link
+	DedupNone DedupScope = "none"
+)
+
+func ParseDedupScope(scope string) (DedupScope, error) {
+	switch DedupScope(scope) {
+	case DedupGlobal, DedupUser, DedupNone:
+		return DedupScope(scope), nil
+	case "":
+		return DedupGlobal, nil
+	}
+	return "", fmt.Errorf("unknown dedup scope %q", scope)
+}
+
+// oldest link owns key of deduplication, links without creation time are created before tracking of it
+func dedupOwnerFirst(URLs []models.ShortenerURL) {
+	sort.SliceStable(URLs, func(i, j int) bool {
+		a, b := URLs[i].CreatedAt, URLs[j].CreatedAt
+		switch {
+		case a == nil && b == nil:
+			return URLs[i].ShortURL < URLs[j].ShortURL
+		case a == nil || b == nil:
+			return a == nil
+		case !a.Equal(*b):
+			return a.Before(*b)
+		}
+		return URLs[i].ShortURL < URLs[j].ShortURL
+	})
+}
+
+// key of link deduplication, empty if link is not deduplicated
+func (d DedupScope) key(URL models.ShortenerURL) string {
+	switch d {
+	case DedupUser:
+		return URL.UserID + "|" + URL.OriginalURL
+	case DedupNone:
+		return ""
+	}
+	return URL.OriginalURL
+}
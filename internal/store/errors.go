@@ -3,8 +3,22 @@ package store
 import "errors"
 
 var (
-	ErrIsNotExists       = errors.New("short link not exist")
-	ErrShortExists       = errors.New("short link exist in database")
-	ErrUserURLsNotExists = errors.New("url with user doesn't exist in database")
-	ErrURLDeleted        = errors.New("short url deleted")
+	ErrIsNotExists        = errors.New("short link not exist")
+	ErrShortExists        = errors.New("short link exist in database")
+	ErrUserURLsNotExists  = errors.New("url with user doesn't exist in database")
+	ErrURLDeleted         = errors.New("short url deleted")
+	ErrUserExists         = errors.New("user with login exist in database")
+	ErrUserNotExists      = errors.New("user doesn't exist in database")
+	ErrSessionNotExists   = errors.New("session doesn't exist in database")
+	ErrAPIKeyNotExists    = errors.New("api key doesn't exist in database")
+	ErrIdentityExists     = errors.New("identity exist in database")
+	ErrIdentityNotExists  = errors.New("identity doesn't exist in database")
+	ErrWorkspaceNotExists = errors.New("workspace doesn't exist in database")
+	ErrMemberNotExists    = errors.New("workspace member doesn't exist in database")
+	ErrURLDisabled        = errors.New("short url disabled")
+	ErrOperationNotExists = errors.New("delete operation doesn't exist in database")
+	ErrLeaderNotExists    = errors.New("leader doesn't exist in database")
+
+	ErrIdempotencyKeyExists    = errors.New("idempotency key exist in database")
+	ErrIdempotencyKeyNotExists = errors.New("idempotency key doesn't exist in database")
 )
@@ -1,37 +1,109 @@
 package store
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"os"
+	"sync"
 
 	"github.com/hollgett/shortener.git/internal/models"
 )
 
 type FileStore struct {
-	file *os.File
+	mu        *sync.Mutex
+	file      *os.File
+	usersFile *os.File
+	auditFile *os.File
+	jobsFile  *os.File
+	opsFile   *os.File
 	*InMemoryStore
 }
 
+// suffixes of files placed near file with URLs
+const (
+	// users, sessions and other account data
+	usersFileSuffix = ".users"
+	// audit log, one json record per line
+	auditFileSuffix = ".audit"
+	// journal of pending and dead delete jobs
+	jobsFileSuffix = ".deletes"
+	// delete operations with results of links
+	opsFileSuffix = ".operations"
+)
+
 // NewFileStore will build filestore based on memory store and return error if problem opening file.
-func NewFileStore(filePath string) (*FileStore, error) {
+func NewFileStore(filePath string, dedup DedupScope) (*FileStore, error) {
 
 	file, err := os.OpenFile(filePath, os.O_RDWR|os.O_CREATE, 0666)
 	if err != nil {
 		return nil, fmt.Errorf("failed open file: %w", err)
 	}
+	usersFile, err := os.OpenFile(filePath+usersFileSuffix, os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, errors.Join(fmt.Errorf("failed open users file: %w", err), file.Close())
+	}
+	auditFile, err := os.OpenFile(filePath+auditFileSuffix, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, errors.Join(fmt.Errorf("failed open audit file: %w", err), file.Close(), usersFile.Close())
+	}
+	jobsFile, err := os.OpenFile(filePath+jobsFileSuffix, os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, errors.Join(fmt.Errorf("failed open delete jobs file: %w", err), file.Close(), usersFile.Close(), auditFile.Close())
+	}
+	opsFile, err := os.OpenFile(filePath+opsFileSuffix, os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, errors.Join(fmt.Errorf("failed open delete operations file: %w", err), file.Close(), usersFile.Close(),
+			auditFile.Close(), jobsFile.Close())
+	}
 	fileStore := FileStore{
+		mu:            &sync.Mutex{},
 		file:          file,
-		InMemoryStore: NewInMemoryStore(),
+		usersFile:     usersFile,
+		auditFile:     auditFile,
+		jobsFile:      jobsFile,
+		opsFile:       opsFile,
+		InMemoryStore: NewInMemoryStore(dedup),
 	}
 	if err := fileStore.restore(); err != nil {
-		return nil, fmt.Errorf("failed restore: %w", err)
+		return nil, errors.Join(fmt.Errorf("failed restore: %w", err), fileStore.Close())
+	}
+	if err := fileStore.restoreUsers(); err != nil {
+		return nil, errors.Join(fmt.Errorf("failed restore users: %w", err), fileStore.Close())
+	}
+	if err := fileStore.restoreAudit(); err != nil {
+		return nil, errors.Join(fmt.Errorf("failed restore audit: %w", err), fileStore.Close())
+	}
+	if err := fileStore.restoreDeleteJobs(); err != nil {
+		return nil, errors.Join(fmt.Errorf("failed restore delete jobs: %w", err), fileStore.Close())
+	}
+	if err := fileStore.restoreDeleteOperations(); err != nil {
+		return nil, errors.Join(fmt.Errorf("failed restore delete operations: %w", err), fileStore.Close())
 	}
 	return &fileStore, nil
 }
 
+// ReadFileURLs read links of file store to memory store, files are not created or changed. Missing file is empty store.
+func ReadFileURLs(filePath string, dedup DedupScope) (*InMemoryStore, error) {
+	m := NewInMemoryStore(dedup)
+	file, err := os.Open(filePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return m, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed open file: %w", err)
+	}
+	defer file.Close()
+
+	URLs := make([]models.ShortenerURL, 0)
+	if err := json.NewDecoder(file).Decode(&URLs); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed decode and read URLs from file: %w", err)
+	}
+	m.loadURLs(URLs)
+	return m, nil
+}
+
 func (f *FileStore) restore() error {
 	if _, err := f.file.Seek(0, io.SeekStart); err != nil {
 		return fmt.Errorf("failed setup in file pointer seek: %w", err)
@@ -39,8 +111,9 @@ func (f *FileStore) restore() error {
 	URLs := make([]models.ShortenerURL, 0)
 	err := json.NewDecoder(f.file).Decode(&URLs)
 	if err == nil {
-		for _, URL := range URLs {
-			f.InMemoryStore.SaveShortURL(URL)
+		// delete time is written once, like database migration does
+		if f.InMemoryStore.loadURLs(URLs) > 0 {
+			return f.update()
 		}
 		return nil
 	} else if errors.Is(err, io.EOF) {
@@ -50,13 +123,13 @@ func (f *FileStore) restore() error {
 }
 
 func (f *FileStore) update() error {
-	if _, err := f.file.Seek(0, io.SeekStart); err != nil {
-		return fmt.Errorf("failed setup in file pointer seek: %w", err)
-	}
-	URLs := make([]models.ShortenerURL, 0)
-	for _, URL := range f.InMemoryStore.URLs {
-		URLs = append(URLs, URL)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := rewind(f.file); err != nil {
+		return err
 	}
+	URLs := f.InMemoryStore.allURLs()
 
 	if err := json.NewEncoder(f.file).Encode(URLs); err != nil {
 		return fmt.Errorf("failed encode and write URLs to file: %w", err)
@@ -64,8 +137,8 @@ func (f *FileStore) update() error {
 	return nil
 }
 
-func (f *FileStore) SaveShortURL(URL models.ShortenerURL) (string, error) {
-	shortExist, err := f.InMemoryStore.SaveShortURL(URL)
+func (f *FileStore) SaveShortURL(ctx context.Context, URL models.ShortenerURL) (string, error) {
+	shortExist, err := f.InMemoryStore.SaveShortURL(ctx, URL)
 	if err == nil {
 		if err := f.update(); err != nil {
 			return "", fmt.Errorf("failed update file: %w", err)
@@ -77,19 +150,36 @@ func (f *FileStore) SaveShortURL(URL models.ShortenerURL) (string, error) {
 	return "", err
 }
 
-func (f *FileStore) SaveShortURLs(URLs []models.ShortenerURL) ([]models.ShortenerURL, error) {
-	urls, err := f.InMemoryStore.SaveShortURLs(URLs)
+func (f *FileStore) SaveShortURLs(ctx context.Context, URLs []models.ShortenerURL) ([]models.ShortenerURL, error) {
+	urls, err := f.InMemoryStore.SaveShortURLs(ctx, URLs)
 	if err != nil {
 		return nil, fmt.Errorf("failed save short urls: %w", err)
 	}
 
-	if err := f.update(); err != nil {
-		return nil, fmt.Errorf("failed update file: %w", err)
+	for _, URL := range urls {
+		if URL.Conflict {
+			continue
+		}
+		if err := f.update(); err != nil {
+			return nil, fmt.Errorf("failed update file: %w", err)
+		}
+		break
 	}
 
 	return urls, nil
 }
 
+// truncate file and set pointer to start for rewrite
+func rewind(file *os.File) error {
+	if err := file.Truncate(0); err != nil {
+		return fmt.Errorf("failed truncate file: %w", err)
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("failed setup in file pointer seek: %w", err)
+	}
+	return nil
+}
+
 func (f *FileStore) Close() error {
-	return f.file.Close()
+	return errors.Join(f.file.Close(), f.usersFile.Close(), f.auditFile.Close(), f.jobsFile.Close(), f.opsFile.Close())
 }
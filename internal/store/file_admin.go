@@ -0,0 +1,69 @@
+package store
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/hollgett/shortener.git/internal/models"
+)
+
+func (f *FileStore) restoreAudit() error {
+	if _, err := f.auditFile.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("failed setup in file pointer seek: %w", err)
+	}
+	scanner := bufio.NewScanner(f.auditFile)
+	for scanner.Scan() {
+		var record models.AuditRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return fmt.Errorf("failed decode audit record: %w", err)
+		}
+		f.InMemoryStore.SaveAuditRecord(record)
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed read audit file: %w", err)
+	}
+	return nil
+}
+
+func (f *FileStore) SetURLDisabled(shortURL string, disabled bool) error {
+	if err := f.InMemoryStore.SetURLDisabled(shortURL, disabled); err != nil {
+		return err
+	}
+	if err := f.update(); err != nil {
+		return fmt.Errorf("failed update file: %w", err)
+	}
+	return nil
+}
+
+func (f *FileStore) ReassignURL(shortURL, userID string) error {
+	if err := f.InMemoryStore.ReassignURL(shortURL, userID); err != nil {
+		return err
+	}
+	if err := f.update(); err != nil {
+		return fmt.Errorf("failed update file: %w", err)
+	}
+	return nil
+}
+
+func (f *FileStore) HardDeleteURL(shortURL string) error {
+	if err := f.InMemoryStore.HardDeleteURL(shortURL); err != nil {
+		return err
+	}
+	if err := f.update(); err != nil {
+		return fmt.Errorf("failed update file: %w", err)
+	}
+	return nil
+}
+
+// append record to end of audit file, file is never rewritten
+func (f *FileStore) SaveAuditRecord(record models.AuditRecord) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := json.NewEncoder(f.auditFile).Encode(record); err != nil {
+		return fmt.Errorf("failed encode and write audit record to file: %w", err)
+	}
+	return f.InMemoryStore.SaveAuditRecord(record)
+}
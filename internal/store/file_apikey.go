@@ -0,0 +1,34 @@
+package store
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hollgett/shortener.git/internal/models"
+)
+
+func (f *FileStore) CreateAPIKey(key models.APIKey) error {
+	if err := f.InMemoryStore.CreateAPIKey(key); err != nil {
+		return err
+	}
+	if err := f.updateUsers(); err != nil {
+		return fmt.Errorf("failed update users file: %w", err)
+	}
+	return nil
+}
+
+func (f *FileStore) RevokeAPIKey(userID, keyID string) error {
+	if err := f.InMemoryStore.RevokeAPIKey(userID, keyID); err != nil {
+		return err
+	}
+	if err := f.updateUsers(); err != nil {
+		return fmt.Errorf("failed update users file: %w", err)
+	}
+	return nil
+}
+
+// last used time is kept in memory only and written to file with next update of users file,
+// so every request with api key doesn't rewrite file
+func (f *FileStore) TouchAPIKey(keyID string, usedAt time.Time) error {
+	return f.InMemoryStore.TouchAPIKey(keyID, usedAt)
+}
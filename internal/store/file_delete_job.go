@@ -0,0 +1,67 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/hollgett/shortener.git/internal/models"
+)
+
+func (f *FileStore) restoreDeleteJobs() error {
+	if _, err := f.jobsFile.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("failed setup in file pointer seek: %w", err)
+	}
+	jobs := make([]models.DeleteJob, 0)
+	err := json.NewDecoder(f.jobsFile).Decode(&jobs)
+	if errors.Is(err, io.EOF) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("failed decode and read delete jobs from file: %w", err)
+	}
+	return f.InMemoryStore.SaveDeleteJobs(jobs)
+}
+
+func (f *FileStore) updateDeleteJobs() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := rewind(f.jobsFile); err != nil {
+		return err
+	}
+	if err := json.NewEncoder(f.jobsFile).Encode(f.InMemoryStore.allDeleteJobs()); err != nil {
+		return fmt.Errorf("failed encode and write delete jobs to file: %w", err)
+	}
+	return nil
+}
+
+func (f *FileStore) SaveDeleteJobs(jobs []models.DeleteJob) error {
+	if err := f.InMemoryStore.SaveDeleteJobs(jobs); err != nil {
+		return err
+	}
+	if err := f.updateDeleteJobs(); err != nil {
+		return fmt.Errorf("failed update delete jobs file: %w", err)
+	}
+	return nil
+}
+
+func (f *FileStore) UpdateDeleteJobs(jobs []models.DeleteJob) error {
+	if err := f.InMemoryStore.UpdateDeleteJobs(jobs); err != nil {
+		return err
+	}
+	if err := f.updateDeleteJobs(); err != nil {
+		return fmt.Errorf("failed update delete jobs file: %w", err)
+	}
+	return nil
+}
+
+func (f *FileStore) RemoveDeleteJobs(IDs []string) error {
+	if err := f.InMemoryStore.RemoveDeleteJobs(IDs); err != nil {
+		return err
+	}
+	if err := f.updateDeleteJobs(); err != nil {
+		return fmt.Errorf("failed update delete jobs file: %w", err)
+	}
+	return nil
+}
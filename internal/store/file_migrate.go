@@ -0,0 +1,18 @@
+package store
+
+import (
+	"fmt"
+
+	"github.com/hollgett/shortener.git/internal/models"
+)
+
+func (f *FileStore) ImportURLs(URLs []models.ShortenerURL) (int, error) {
+	imported, err := f.InMemoryStore.ImportURLs(URLs)
+	if err != nil || imported == 0 {
+		return imported, err
+	}
+	if err := f.update(); err != nil {
+		return 0, fmt.Errorf("failed update file: %w", err)
+	}
+	return imported, nil
+}
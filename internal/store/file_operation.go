@@ -0,0 +1,62 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/hollgett/shortener.git/internal/models"
+)
+
+func (f *FileStore) restoreDeleteOperations() error {
+	if _, err := f.opsFile.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("failed setup in file pointer seek: %w", err)
+	}
+	operations := make([]models.DeleteOperation, 0)
+	err := json.NewDecoder(f.opsFile).Decode(&operations)
+	if errors.Is(err, io.EOF) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("failed decode and read delete operations from file: %w", err)
+	}
+	for _, operation := range operations {
+		if err := f.InMemoryStore.CreateDeleteOperation(operation); err != nil {
+			return fmt.Errorf("failed restore delete operation: %w", err)
+		}
+	}
+	return nil
+}
+
+func (f *FileStore) updateDeleteOperations() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := rewind(f.opsFile); err != nil {
+		return err
+	}
+	if err := json.NewEncoder(f.opsFile).Encode(f.InMemoryStore.allDeleteOperations()); err != nil {
+		return fmt.Errorf("failed encode and write delete operations to file: %w", err)
+	}
+	return nil
+}
+
+func (f *FileStore) CreateDeleteOperation(operation models.DeleteOperation) error {
+	if err := f.InMemoryStore.CreateDeleteOperation(operation); err != nil {
+		return err
+	}
+	if err := f.updateDeleteOperations(); err != nil {
+		return fmt.Errorf("failed update delete operations file: %w", err)
+	}
+	return nil
+}
+
+func (f *FileStore) SetDeleteResults(operationID string, results []models.DeleteResult) error {
+	if err := f.InMemoryStore.SetDeleteResults(operationID, results); err != nil {
+		return err
+	}
+	if err := f.updateDeleteOperations(); err != nil {
+		return fmt.Errorf("failed update delete operations file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,27 @@
+package store
+
+import (
+	"fmt"
+
+	"github.com/hollgett/shortener.git/internal/models"
+)
+
+func (f *FileStore) SetURLRedirect(shortURL string, policy models.RedirectPolicy) error {
+	if err := f.InMemoryStore.SetURLRedirect(shortURL, policy); err != nil {
+		return err
+	}
+	if err := f.update(); err != nil {
+		return fmt.Errorf("failed update file: %w", err)
+	}
+	return nil
+}
+
+func (f *FileStore) SetURLTitle(shortURL, title string) error {
+	if err := f.InMemoryStore.SetURLTitle(shortURL, title); err != nil {
+		return err
+	}
+	if err := f.update(); err != nil {
+		return fmt.Errorf("failed update file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package store
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hollgett/shortener.git/internal/models"
+)
+
+func TestFileStoreBackfillDeletedAt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "urls.json")
+	src, err := json.Marshal([]models.ShortenerURL{
+		{ShortURL: "deleted", OriginalURL: "https://a.example", UserID: "u", DeletedFlag: true},
+		{ShortURL: "live", OriginalURL: "https://b.example", UserID: "u"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, src, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	// delete time of every open, it must be set by first open and kept by next ones
+	var deleted []models.ShortenerURL
+	for range 2 {
+		f, err := NewFileStore(path, DedupGlobal)
+		if err != nil {
+			t.Fatalf("NewFileStore() error = %v", err)
+		}
+		if err := f.Close(); err != nil {
+			t.Fatalf("Close() error = %v", err)
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var URLs []models.ShortenerURL
+		if err := json.Unmarshal(data, &URLs); err != nil {
+			t.Fatalf("saved file = %s, error = %v", data, err)
+		}
+		for _, URL := range URLs {
+			switch {
+			case URL.ShortURL == "live" && URL.DeletedAt != nil:
+				t.Errorf("live link got delete time %v", URL.DeletedAt)
+			case URL.ShortURL == "deleted" && URL.DeletedAt == nil:
+				t.Fatalf("delete time of deleted link is not saved")
+			case URL.ShortURL == "deleted":
+				deleted = append(deleted, URL)
+			}
+		}
+	}
+	if len(deleted) != 2 || !deleted[0].DeletedAt.Equal(*deleted[1].DeletedAt) {
+		t.Errorf("delete time is changed on next open: %+v", deleted)
+	}
+}
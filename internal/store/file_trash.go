@@ -0,0 +1,46 @@
+package store
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hollgett/shortener.git/internal/models"
+)
+
+func (f *FileStore) DeleteURLs(URLs []models.DeleteURL) error {
+	if err := f.InMemoryStore.DeleteURLs(URLs); err != nil {
+		return err
+	}
+	if err := f.update(); err != nil {
+		return fmt.Errorf("failed update file: %w", err)
+	}
+	return nil
+}
+
+func (f *FileStore) RestoreURLs(userID string, shortURLs []string) ([]string, error) {
+	restored, err := f.InMemoryStore.RestoreURLs(userID, shortURLs)
+	if err != nil {
+		return nil, err
+	}
+	if len(restored) == 0 {
+		return restored, nil
+	}
+	if err := f.update(); err != nil {
+		return nil, fmt.Errorf("failed update file: %w", err)
+	}
+	return restored, nil
+}
+
+func (f *FileStore) PurgeDeletedURLs(deletedBefore time.Time) (int, error) {
+	purged, err := f.InMemoryStore.PurgeDeletedURLs(deletedBefore)
+	if err != nil {
+		return 0, err
+	}
+	if purged == 0 {
+		return 0, nil
+	}
+	if err := f.update(); err != nil {
+		return 0, fmt.Errorf("failed update file: %w", err)
+	}
+	return purged, nil
+}
@@ -0,0 +1,144 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/hollgett/shortener.git/internal/models"
+)
+
+// content of users file
+type fileUsers struct {
+	Users      []models.User            `json:"users"`
+	Sessions   []models.Session         `json:"sessions"`
+	APIKeys    []models.APIKey          `json:"api_keys"`
+	Identities []models.UserIdentity    `json:"identities"`
+	Workspaces []models.Workspace       `json:"workspaces"`
+	Members    []models.WorkspaceMember `json:"workspace_members"`
+}
+
+func (f *FileStore) restoreUsers() error {
+	if _, err := f.usersFile.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("failed setup in file pointer seek: %w", err)
+	}
+	var data fileUsers
+	err := json.NewDecoder(f.usersFile).Decode(&data)
+	if errors.Is(err, io.EOF) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("failed decode and read users from file: %w", err)
+	}
+
+	for _, user := range data.Users {
+		if err := f.InMemoryStore.CreateUser(user); err != nil {
+			return fmt.Errorf("failed restore user %s: %w", user.Login, err)
+		}
+	}
+	for _, session := range data.Sessions {
+		if err := f.InMemoryStore.CreateSession(session); err != nil {
+			return fmt.Errorf("failed restore session: %w", err)
+		}
+	}
+	for _, key := range data.APIKeys {
+		if err := f.InMemoryStore.CreateAPIKey(key); err != nil {
+			return fmt.Errorf("failed restore api key: %w", err)
+		}
+	}
+	for _, identity := range data.Identities {
+		if err := f.InMemoryStore.CreateUserIdentity(identity); err != nil {
+			return fmt.Errorf("failed restore identity: %w", err)
+		}
+	}
+	// store is not shared yet, so maps are filled without lock
+	for _, workspace := range data.Workspaces {
+		f.InMemoryStore.Workspaces[workspace.ID] = workspace
+		f.InMemoryStore.Members[workspace.ID] = make(map[string]models.WorkspaceMember)
+	}
+	for _, member := range data.Members {
+		if err := f.InMemoryStore.SaveWorkspaceMember(member); err != nil {
+			return fmt.Errorf("failed restore workspace member: %w", err)
+		}
+	}
+	return nil
+}
+
+func (f *FileStore) updateUsers() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := rewind(f.usersFile); err != nil {
+		return err
+	}
+	var data fileUsers
+	data.Users, data.Sessions = f.InMemoryStore.allUsers()
+	data.APIKeys = f.InMemoryStore.allAPIKeys()
+	data.Identities = f.InMemoryStore.allIdentities()
+	data.Workspaces, data.Members = f.InMemoryStore.allWorkspaces()
+
+	if err := json.NewEncoder(f.usersFile).Encode(data); err != nil {
+		return fmt.Errorf("failed encode and write users to file: %w", err)
+	}
+	return nil
+}
+
+func (f *FileStore) CreateUser(user models.User) error {
+	if err := f.InMemoryStore.CreateUser(user); err != nil {
+		return err
+	}
+	if err := f.updateUsers(); err != nil {
+		return fmt.Errorf("failed update users file: %w", err)
+	}
+	return nil
+}
+
+func (f *FileStore) ReassignUserURLs(fromUserID, toUserID string) error {
+	if err := f.InMemoryStore.ReassignUserURLs(fromUserID, toUserID); err != nil {
+		return err
+	}
+	if err := f.update(); err != nil {
+		return fmt.Errorf("failed update file: %w", err)
+	}
+	return nil
+}
+
+func (f *FileStore) CreateSession(session models.Session) error {
+	if err := f.InMemoryStore.CreateSession(session); err != nil {
+		return err
+	}
+	if err := f.updateUsers(); err != nil {
+		return fmt.Errorf("failed update users file: %w", err)
+	}
+	return nil
+}
+
+func (f *FileStore) RevokeSession(sessionID string) error {
+	if err := f.InMemoryStore.RevokeSession(sessionID); err != nil {
+		return err
+	}
+	if err := f.updateUsers(); err != nil {
+		return fmt.Errorf("failed update users file: %w", err)
+	}
+	return nil
+}
+
+func (f *FileStore) RevokeUserSessions(userID string) error {
+	if err := f.InMemoryStore.RevokeUserSessions(userID); err != nil {
+		return err
+	}
+	if err := f.updateUsers(); err != nil {
+		return fmt.Errorf("failed update users file: %w", err)
+	}
+	return nil
+}
+
+func (f *FileStore) CreateUserIdentity(identity models.UserIdentity) error {
+	if err := f.InMemoryStore.CreateUserIdentity(identity); err != nil {
+		return err
+	}
+	if err := f.updateUsers(); err != nil {
+		return fmt.Errorf("failed update users file: %w", err)
+	}
+	return nil
+}
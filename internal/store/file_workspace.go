@@ -0,0 +1,67 @@
+package store
+
+import (
+	"fmt"
+
+	"github.com/hollgett/shortener.git/internal/models"
+)
+
+func (f *FileStore) CreateWorkspace(workspace models.Workspace, owner models.WorkspaceMember) error {
+	if err := f.InMemoryStore.CreateWorkspace(workspace, owner); err != nil {
+		return err
+	}
+	if err := f.updateUsers(); err != nil {
+		return fmt.Errorf("failed update users file: %w", err)
+	}
+	return nil
+}
+
+func (f *FileStore) SaveWorkspaceMember(member models.WorkspaceMember) error {
+	if err := f.InMemoryStore.SaveWorkspaceMember(member); err != nil {
+		return err
+	}
+	if err := f.updateUsers(); err != nil {
+		return fmt.Errorf("failed update users file: %w", err)
+	}
+	return nil
+}
+
+func (f *FileStore) DeleteWorkspaceMember(workspaceID, userID string) error {
+	if err := f.InMemoryStore.DeleteWorkspaceMember(workspaceID, userID); err != nil {
+		return err
+	}
+	if err := f.updateUsers(); err != nil {
+		return fmt.Errorf("failed update users file: %w", err)
+	}
+	return nil
+}
+
+func (f *FileStore) MoveURLToWorkspace(userID, shortURL, workspaceID string) error {
+	if err := f.InMemoryStore.MoveURLToWorkspace(userID, shortURL, workspaceID); err != nil {
+		return err
+	}
+	if err := f.update(); err != nil {
+		return fmt.Errorf("failed update file: %w", err)
+	}
+	return nil
+}
+
+func (f *FileStore) UpdateWorkspaceURL(workspaceID, shortURL, originalURL string) error {
+	if err := f.InMemoryStore.UpdateWorkspaceURL(workspaceID, shortURL, originalURL); err != nil {
+		return err
+	}
+	if err := f.update(); err != nil {
+		return fmt.Errorf("failed update file: %w", err)
+	}
+	return nil
+}
+
+func (f *FileStore) DeleteWorkspaceURLs(workspaceID string, shortURLs []string) error {
+	if err := f.InMemoryStore.DeleteWorkspaceURLs(workspaceID, shortURLs); err != nil {
+		return err
+	}
+	if err := f.update(); err != nil {
+		return fmt.Errorf("failed update file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package store
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// bounded cache with least recently used eviction and expiry of entries
+type lruCache[V any] struct {
+	mu      *sync.Mutex
+	size    int
+	items   map[string]*list.Element
+	order   *list.List
+	onEvict func()
+}
+
+type lruEntry[V any] struct {
+	key     string
+	value   V
+	expires time.Time
+}
+
+func newLRUCache[V any](size int, onEvict func()) *lruCache[V] {
+	return &lruCache[V]{
+		mu:      &sync.Mutex{},
+		size:    size,
+		items:   make(map[string]*list.Element),
+		order:   list.New(),
+		onEvict: onEvict,
+	}
+}
+
+// get value if it isn't expired, expired entry is removed
+func (c *lruCache[V]) get(key string) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var zero V
+	elem, ok := c.items[key]
+	if !ok {
+		return zero, false
+	}
+	entry := elem.Value.(*lruEntry[V])
+	if !time.Now().Before(entry.expires) {
+		c.order.Remove(elem)
+		delete(c.items, key)
+		return zero, false
+	}
+	c.order.MoveToFront(elem)
+	return entry.value, true
+}
+
+// set value with lifetime, least recently used entry is evicted when cache is full
+func (c *lruCache[V]) set(key string, value V, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expires := time.Now().Add(ttl)
+	if elem, ok := c.items[key]; ok {
+		elem.Value = &lruEntry[V]{key: key, value: value, expires: expires}
+		c.order.MoveToFront(elem)
+		return
+	}
+	c.items[key] = c.order.PushFront(&lruEntry[V]{key: key, value: value, expires: expires})
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*lruEntry[V]).key)
+		if c.onEvict != nil {
+			c.onEvict()
+		}
+	}
+}
+
+func (c *lruCache[V]) remove(keys ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, key := range keys {
+		if elem, ok := c.items[key]; ok {
+			c.order.Remove(elem)
+			delete(c.items, key)
+		}
+	}
+}
+
+func (c *lruCache[V]) clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.items = make(map[string]*list.Element)
+	c.order.Init()
+}
+
+func (c *lruCache[V]) len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.order.Len()
+}
@@ -1,60 +1,242 @@
 package store
 
 import (
+	"context"
+	"sync"
+	"time"
+
 	"github.com/hollgett/shortener.git/internal/models"
 )
 
 type InMemoryStore struct {
+	mu    *sync.RWMutex
+	dedup DedupScope
 	// key short link
 	URLs map[string]models.ShortenerURL
-	//key original, value short
+	// key dedup key of original, value short
 	OriginalURLs map[string]string
 	// key user id, value URL
 	UserURLs map[string]models.ShortenerURL
+	// key user id
+	Users map[string]models.User
+	// key login, value user id
+	Logins map[string]string
+	// key session id
+	Sessions map[string]models.Session
+	// key api key id
+	APIKeys map[string]models.APIKey
+	// key issuer and subject of external identity
+	Identities map[identityKey]models.UserIdentity
+	// key workspace id
+	Workspaces map[string]models.Workspace
+	// key workspace id, value members by user id
+	Members map[string]map[string]models.WorkspaceMember
+	// append-only, oldest first
+	AuditRecords []models.AuditRecord
+	// key delete job id
+	DeleteJobs map[string]models.DeleteJob
+	// key delete operation id
+	DeleteOperations map[string]models.DeleteOperation
+	// key lock name
+	Leaders map[string]models.Leader
+	// key user id and idempotency key
+	IdempotencyKeys map[idempotencyKey]models.IdempotencyRecord
+	// counters for statistics, changed with URLs by putURL and removeURL
+	userURLsCount map[string]int
+	deletedCount  int
+}
+
+type identityKey struct {
+	issuer  string
+	subject string
 }
 
 // build in memory store
-func NewInMemoryStore() *InMemoryStore {
+func NewInMemoryStore(dedup DedupScope) *InMemoryStore {
 	return &InMemoryStore{
+		mu:           &sync.RWMutex{},
+		dedup:        dedup,
 		URLs:         make(map[string]models.ShortenerURL),
 		OriginalURLs: make(map[string]string),
 		UserURLs:     make(map[string]models.ShortenerURL),
+		Users:        make(map[string]models.User),
+		Logins:       make(map[string]string),
+		Sessions:     make(map[string]models.Session),
+		APIKeys:      make(map[string]models.APIKey),
+		Identities:   make(map[identityKey]models.UserIdentity),
+		Workspaces:   make(map[string]models.Workspace),
+		Members:      make(map[string]map[string]models.WorkspaceMember),
+		DeleteJobs:   make(map[string]models.DeleteJob),
+
+		DeleteOperations: make(map[string]models.DeleteOperation),
+		Leaders:          make(map[string]models.Leader),
+		IdempotencyKeys:  make(map[idempotencyKey]models.IdempotencyRecord),
+
+		userURLsCount: make(map[string]int),
 	}
 }
 
-func (m *InMemoryStore) SaveShortURL(URL models.ShortenerURL) (string, error) {
-	if existShort, ok := m.OriginalURLs[URL.OriginalURL]; ok {
+func (m *InMemoryStore) SaveShortURL(_ context.Context, URL models.ShortenerURL) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := m.dedup.key(URL)
+	if existShort, ok := m.OriginalURLs[key]; ok {
 		return existShort, ErrShortExists
 	}
-	m.URLs[URL.ShortURL] = URL
-	m.OriginalURLs[URL.OriginalURL] = URL.ShortURL
+	m.putURL(URL)
+	m.indexURL(URL)
 	return "", nil
 }
 
-func (m *InMemoryStore) SaveShortURLs(URLs []models.ShortenerURL) ([]models.ShortenerURL, error) {
-	for _, v := range URLs {
-		m.URLs[v.ShortURL] = v
-		m.OriginalURLs[v.OriginalURL] = v.ShortURL
-	}
-	return URLs, nil
-}
+func (m *InMemoryStore) SaveShortURLs(_ context.Context, URLs []models.ShortenerURL) ([]models.ShortenerURL, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 
-func (m *InMemoryStore) GetOriginalURL(ShortLink string) (string, error) {
-	URL, ok := m.URLs[ShortLink]
-	if !ok {
-		return "", ErrIsNotExists
+	saved := make([]models.ShortenerURL, len(URLs))
+	for i, v := range URLs {
+		key := m.dedup.key(v)
+		if existShort, ok := m.OriginalURLs[key]; ok {
+			v.ShortURL = existShort
+			v.Conflict = true
+		} else {
+			m.putURL(v)
+			m.indexURL(v)
+		}
+		saved[i] = v
 	}
-	return URL.OriginalURL, nil
+	return saved, nil
 }
 
 func (m *InMemoryStore) GetUserURLs(userID string) ([]models.URLResponse, error) {
-	return nil, nil
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	userURLs := make([]models.URLResponse, 0)
+	for _, URL := range m.URLs {
+		if URL.UserID == userID && len(URL.WorkspaceID) == 0 {
+			userURLs = append(userURLs, models.URLResponse{
+				ShortURL:    URL.ShortURL,
+				OriginalURL: URL.OriginalURL,
+			})
+		}
+	}
+	if len(userURLs) == 0 {
+		return nil, ErrUserURLsNotExists
+	}
+	return userURLs, nil
 }
 
 func (m *InMemoryStore) DeleteURLs(URLs []models.DeleteURL) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now().UTC()
+	for _, v := range URLs {
+		URL, ok := m.URLs[v.ShortURL]
+		if !ok || URL.UserID != v.UserID || len(URL.WorkspaceID) != 0 || URL.DeletedFlag {
+			continue
+		}
+		URL.DeletedFlag = true
+		URL.DeletedAt = &now
+		m.putURL(URL)
+	}
 	return nil
 }
 
+func (m *InMemoryStore) GetStats() (models.Stats, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return models.Stats{
+		URLs:    len(m.URLs),
+		Users:   len(m.userURLsCount),
+		Deleted: m.deletedCount,
+	}, nil
+}
+
+// save URL by short link and update counters, caller must hold write lock
+func (m *InMemoryStore) putURL(URL models.ShortenerURL) {
+	if old, ok := m.URLs[URL.ShortURL]; ok {
+		m.uncount(old)
+	}
+	m.URLs[URL.ShortURL] = URL
+	m.userURLsCount[URL.UserID]++
+	if URL.DeletedFlag {
+		m.deletedCount++
+	}
+}
+
+// remove URL with its original and update counters, caller must hold write lock
+func (m *InMemoryStore) removeURL(URL models.ShortenerURL) {
+	if _, ok := m.URLs[URL.ShortURL]; !ok {
+		return
+	}
+	m.uncount(m.URLs[URL.ShortURL])
+	delete(m.URLs, URL.ShortURL)
+	m.unindexURL(URL)
+}
+
+// index URL by dedup key if key is not taken by other link, caller must hold write lock
+func (m *InMemoryStore) indexURL(URL models.ShortenerURL) {
+	key := m.dedup.key(URL)
+	if _, ok := m.OriginalURLs[key]; ok || key == "" {
+		return
+	}
+	m.OriginalURLs[key] = URL.ShortURL
+}
+
+// remove URL from dedup index if it owns key, caller must hold write lock
+func (m *InMemoryStore) unindexURL(URL models.ShortenerURL) {
+	key := m.dedup.key(URL)
+	if m.OriginalURLs[key] == URL.ShortURL {
+		delete(m.OriginalURLs, key)
+	}
+}
+
+// load saved URLs, links duplicated in current scope are kept without index and key is owned by oldest link.
+//
+// links deleted before delete time was saved get time of load, so retention starts now like in migration of database.
+// Return count of such links, caller saves them so time of load is not moved on next start.
+func (m *InMemoryStore) loadURLs(URLs []models.ShortenerURL) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	dedupOwnerFirst(URLs)
+	now := time.Now().UTC()
+	backfilled := 0
+	for _, URL := range URLs {
+		if URL.DeletedFlag && URL.DeletedAt == nil {
+			URL.DeletedAt = &now
+			backfilled++
+		}
+		m.putURL(URL)
+		m.indexURL(URL)
+	}
+	return backfilled
+}
+
+func (m *InMemoryStore) uncount(URL models.ShortenerURL) {
+	if m.userURLsCount[URL.UserID]--; m.userURLsCount[URL.UserID] <= 0 {
+		delete(m.userURLsCount, URL.UserID)
+	}
+	if URL.DeletedFlag {
+		m.deletedCount--
+	}
+}
+
+// snapshot of all URLs
+func (m *InMemoryStore) allURLs() []models.ShortenerURL {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	URLs := make([]models.ShortenerURL, 0, len(m.URLs))
+	for _, URL := range m.URLs {
+		URLs = append(URLs, URL)
+	}
+	return URLs
+}
+
 func (m *InMemoryStore) Close() error {
 	return nil
 }
@@ -0,0 +1,134 @@
+package store
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/hollgett/shortener.git/internal/models"
+)
+
+func (m *InMemoryStore) SearchURLs(filter models.AdminURLFilter) ([]models.AdminURL, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	original := strings.ToLower(filter.OriginalURL)
+	URLs := make([]models.AdminURL, 0)
+	for _, URL := range m.URLs {
+		switch {
+		case len(filter.ShortURL) != 0 && URL.ShortURL != filter.ShortURL:
+			continue
+		case len(filter.UserID) != 0 && URL.UserID != filter.UserID:
+			continue
+		case len(original) != 0 && !strings.Contains(strings.ToLower(URL.OriginalURL), original):
+			continue
+		}
+		URLs = append(URLs, toAdminURL(URL))
+	}
+
+	sort.Slice(URLs, func(i, j int) bool { return URLs[i].ShortURL < URLs[j].ShortURL })
+	if filter.Limit > 0 && len(URLs) > filter.Limit {
+		URLs = URLs[:filter.Limit]
+	}
+	return URLs, nil
+}
+
+func (m *InMemoryStore) GetURL(shortURL string) (models.AdminURL, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	URL, ok := m.URLs[shortURL]
+	if !ok {
+		return models.AdminURL{}, ErrIsNotExists
+	}
+	return toAdminURL(URL), nil
+}
+
+func (m *InMemoryStore) GetURLs(shortURLs []string) ([]models.AdminURL, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	URLs := make([]models.AdminURL, 0, len(shortURLs))
+	for _, short := range shortURLs {
+		if URL, ok := m.URLs[short]; ok {
+			URLs = append(URLs, toAdminURL(URL))
+		}
+	}
+	return URLs, nil
+}
+
+func (m *InMemoryStore) SetURLDisabled(shortURL string, disabled bool) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	URL, ok := m.URLs[shortURL]
+	if !ok {
+		return ErrIsNotExists
+	}
+	URL.DisabledFlag = disabled
+	m.putURL(URL)
+	return nil
+}
+
+func (m *InMemoryStore) ReassignURL(shortURL, userID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	URL, ok := m.URLs[shortURL]
+	if !ok {
+		return ErrIsNotExists
+	}
+	URL.UserID = userID
+	m.putURL(URL)
+	return nil
+}
+
+func (m *InMemoryStore) HardDeleteURL(shortURL string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	URL, ok := m.URLs[shortURL]
+	if !ok {
+		return ErrIsNotExists
+	}
+	m.removeURL(URL)
+	return nil
+}
+
+func (m *InMemoryStore) SaveAuditRecord(record models.AuditRecord) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.AuditRecords = append(m.AuditRecords, record)
+	return nil
+}
+
+func (m *InMemoryStore) GetAuditRecords(filter models.AuditFilter) ([]models.AuditRecord, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	records := make([]models.AuditRecord, 0)
+	for i := len(m.AuditRecords) - 1; i >= 0; i-- {
+		if filter.Limit > 0 && len(records) == filter.Limit {
+			break
+		}
+		if filter.Match(m.AuditRecords[i]) {
+			records = append(records, m.AuditRecords[i])
+		}
+	}
+	return records, nil
+}
+
+func toAdminURL(URL models.ShortenerURL) models.AdminURL {
+	return models.AdminURL{
+		ShortURL:    URL.ShortURL,
+		OriginalURL: URL.OriginalURL,
+		UserID:      URL.UserID,
+		WorkspaceID: URL.WorkspaceID,
+		Deleted:     URL.DeletedFlag,
+		Disabled:    URL.DisabledFlag,
+		Title:       URL.Title,
+		CreatedAt:   URL.CreatedAt,
+
+		RedirectPolicy: URL.RedirectPolicy,
+	}
+}
@@ -0,0 +1,77 @@
+package store
+
+import (
+	"time"
+
+	"github.com/hollgett/shortener.git/internal/models"
+)
+
+func (m *InMemoryStore) CreateAPIKey(key models.APIKey) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.APIKeys[key.ID] = key
+	return nil
+}
+
+func (m *InMemoryStore) GetAPIKey(keyID string) (models.APIKey, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	key, ok := m.APIKeys[keyID]
+	if !ok {
+		return models.APIKey{}, ErrAPIKeyNotExists
+	}
+	return key, nil
+}
+
+func (m *InMemoryStore) GetUserAPIKeys(userID string) ([]models.APIKey, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	keys := make([]models.APIKey, 0)
+	for _, key := range m.APIKeys {
+		if key.UserID == userID {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+func (m *InMemoryStore) RevokeAPIKey(userID, keyID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key, ok := m.APIKeys[keyID]
+	if !ok || key.UserID != userID {
+		return ErrAPIKeyNotExists
+	}
+	key.Revoked = true
+	m.APIKeys[keyID] = key
+	return nil
+}
+
+func (m *InMemoryStore) TouchAPIKey(keyID string, usedAt time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key, ok := m.APIKeys[keyID]
+	if !ok {
+		return ErrAPIKeyNotExists
+	}
+	key.LastUsedAt = &usedAt
+	m.APIKeys[keyID] = key
+	return nil
+}
+
+// snapshot of api keys
+func (m *InMemoryStore) allAPIKeys() []models.APIKey {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	keys := make([]models.APIKey, 0, len(m.APIKeys))
+	for _, key := range m.APIKeys {
+		keys = append(keys, key)
+	}
+	return keys
+}
@@ -0,0 +1,91 @@
+package store
+
+import (
+	"sort"
+	"time"
+
+	"github.com/hollgett/shortener.git/internal/models"
+)
+
+func (m *InMemoryStore) SaveDeleteJobs(jobs []models.DeleteJob) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, job := range jobs {
+		m.DeleteJobs[job.ID] = job
+	}
+	return nil
+}
+
+// claim of file store is written with next change of jobs, jobs of stopped process are run when it is expired
+func (m *InMemoryStore) ClaimDueDeleteJobs(now, leaseUntil time.Time, limit int) ([]models.DeleteJob, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	jobs := make([]models.DeleteJob, 0)
+	for _, job := range m.DeleteJobs {
+		if !job.Dead && !job.NextAttemptAt.After(now) {
+			jobs = append(jobs, job)
+		}
+	}
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].CreatedAt.Before(jobs[j].CreatedAt) })
+	if limit > 0 && len(jobs) > limit {
+		jobs = jobs[:limit]
+	}
+	for i := range jobs {
+		jobs[i].NextAttemptAt = leaseUntil
+		m.DeleteJobs[jobs[i].ID] = jobs[i]
+	}
+	return jobs, nil
+}
+
+func (m *InMemoryStore) UpdateDeleteJobs(jobs []models.DeleteJob) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, job := range jobs {
+		if _, ok := m.DeleteJobs[job.ID]; ok {
+			m.DeleteJobs[job.ID] = job
+		}
+	}
+	return nil
+}
+
+func (m *InMemoryStore) RemoveDeleteJobs(IDs []string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, ID := range IDs {
+		delete(m.DeleteJobs, ID)
+	}
+	return nil
+}
+
+func (m *InMemoryStore) GetDeadDeleteJobs(limit int) ([]models.DeleteJob, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	jobs := make([]models.DeleteJob, 0)
+	for _, job := range m.DeleteJobs {
+		if job.Dead {
+			jobs = append(jobs, job)
+		}
+	}
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].CreatedAt.After(jobs[j].CreatedAt) })
+	if limit > 0 && len(jobs) > limit {
+		jobs = jobs[:limit]
+	}
+	return jobs, nil
+}
+
+// snapshot of all delete jobs
+func (m *InMemoryStore) allDeleteJobs() []models.DeleteJob {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	jobs := make([]models.DeleteJob, 0, len(m.DeleteJobs))
+	for _, job := range m.DeleteJobs {
+		jobs = append(jobs, job)
+	}
+	return jobs
+}
@@ -0,0 +1,73 @@
+package store
+
+import (
+	"time"
+
+	"github.com/hollgett/shortener.git/internal/models"
+)
+
+// keys are kept only in memory by file store too, they live no longer than idempotency window
+type idempotencyKey struct {
+	userID string
+	key    string
+}
+
+func (m *InMemoryStore) SaveIdempotencyKey(record models.IdempotencyRecord) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	id := idempotencyKey{userID: record.UserID, key: record.Key}
+	if saved, ok := m.IdempotencyKeys[id]; ok && saved.ExpiresAt.After(record.CreatedAt) {
+		return ErrIdempotencyKeyExists
+	}
+	m.IdempotencyKeys[id] = record
+	return nil
+}
+
+func (m *InMemoryStore) GetIdempotencyKey(userID, key string) (models.IdempotencyRecord, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	record, ok := m.IdempotencyKeys[idempotencyKey{userID: userID, key: key}]
+	if !ok {
+		return models.IdempotencyRecord{}, ErrIdempotencyKeyNotExists
+	}
+	return record, nil
+}
+
+func (m *InMemoryStore) CompleteIdempotencyKey(record models.IdempotencyRecord) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	id := idempotencyKey{userID: record.UserID, key: record.Key}
+	saved, ok := m.IdempotencyKeys[id]
+	if !ok || !saved.CreatedAt.Equal(record.CreatedAt) {
+		return ErrIdempotencyKeyNotExists
+	}
+	saved.Status, saved.ContentType, saved.Body = record.Status, record.ContentType, record.Body
+	saved.ExpiresAt = record.ExpiresAt
+	m.IdempotencyKeys[id] = saved
+	return nil
+}
+
+func (m *InMemoryStore) DeleteIdempotencyKey(userID, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.IdempotencyKeys, idempotencyKey{userID: userID, key: key})
+	return nil
+}
+
+func (m *InMemoryStore) PurgeIdempotencyKeys(expiredBefore time.Time) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	purged := 0
+	for id, record := range m.IdempotencyKeys {
+		if !record.ExpiresAt.After(expiredBefore) {
+			delete(m.IdempotencyKeys, id)
+			purged++
+		}
+	}
+	return purged, nil
+}
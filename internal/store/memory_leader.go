@@ -0,0 +1,70 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/hollgett/shortener.git/internal/models"
+)
+
+// lock inside of process, store is not shared by instances so it is always free for single elector
+type memoryLeaderLock struct {
+	m          *InMemoryStore
+	name       string
+	instanceID string
+}
+
+func (m *InMemoryStore) NewLeaderLock(name, instanceID string) LeaderLock {
+	return &memoryLeaderLock{m: m, name: name, instanceID: instanceID}
+}
+
+func (m *InMemoryStore) GetLeader(name string) (models.Leader, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	leader, ok := m.Leaders[name]
+	if !ok {
+		return models.Leader{}, ErrLeaderNotExists
+	}
+	return leader, nil
+}
+
+func (l *memoryLeaderLock) TryLock(ctx context.Context) (bool, error) {
+	l.m.mu.Lock()
+	defer l.m.mu.Unlock()
+
+	if leader, ok := l.m.Leaders[l.name]; ok && leader.InstanceID != l.instanceID {
+		return false, nil
+	}
+	now := time.Now().UTC()
+	l.m.Leaders[l.name] = models.Leader{
+		Name:       l.name,
+		InstanceID: l.instanceID,
+		AcquiredAt: now,
+		RenewedAt:  now,
+	}
+	return true, nil
+}
+
+func (l *memoryLeaderLock) Renew(ctx context.Context) error {
+	l.m.mu.Lock()
+	defer l.m.mu.Unlock()
+
+	leader, ok := l.m.Leaders[l.name]
+	if !ok || leader.InstanceID != l.instanceID {
+		return ErrLeaderNotExists
+	}
+	leader.RenewedAt = time.Now().UTC()
+	l.m.Leaders[l.name] = leader
+	return nil
+}
+
+func (l *memoryLeaderLock) Unlock(ctx context.Context) error {
+	l.m.mu.Lock()
+	defer l.m.mu.Unlock()
+
+	if leader, ok := l.m.Leaders[l.name]; ok && leader.InstanceID == l.instanceID {
+		delete(l.m.Leaders, l.name)
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package store
+
+import (
+	"sort"
+
+	"github.com/hollgett/shortener.git/internal/models"
+)
+
+func (m *InMemoryStore) ScanURLs(userID, afterShort string, limit int) ([]models.ShortenerURL, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	URLs := make([]models.ShortenerURL, 0)
+	for short, URL := range m.URLs {
+		if short > afterShort && (userID == "" || URL.UserID == userID && len(URL.WorkspaceID) == 0) {
+			URLs = append(URLs, URL)
+		}
+	}
+	sort.Slice(URLs, func(i, j int) bool { return URLs[i].ShortURL < URLs[j].ShortURL })
+	if limit > 0 && len(URLs) > limit {
+		URLs = URLs[:limit]
+	}
+	return URLs, nil
+}
+
+func (m *InMemoryStore) ImportURLs(URLs []models.ShortenerURL) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	imported := 0
+	for _, URL := range URLs {
+		if _, ok := m.URLs[URL.ShortURL]; ok {
+			continue
+		}
+		m.putURL(URL)
+		m.indexURL(URL)
+		imported++
+	}
+	return imported, nil
+}
@@ -0,0 +1,62 @@
+package store
+
+import (
+	"time"
+
+	"github.com/hollgett/shortener.git/internal/models"
+)
+
+func (m *InMemoryStore) CreateDeleteOperation(operation models.DeleteOperation) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	operation.Results = append([]models.DeleteResult(nil), operation.Results...)
+	m.DeleteOperations[operation.ID] = operation
+	return nil
+}
+
+func (m *InMemoryStore) GetDeleteOperation(operationID string) (models.DeleteOperation, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	operation, ok := m.DeleteOperations[operationID]
+	if !ok {
+		return models.DeleteOperation{}, ErrOperationNotExists
+	}
+	// results are changed in place by SetDeleteResults
+	operation.Results = append([]models.DeleteResult(nil), operation.Results...)
+	return operation, nil
+}
+
+func (m *InMemoryStore) SetDeleteResults(operationID string, results []models.DeleteResult) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	operation, ok := m.DeleteOperations[operationID]
+	if !ok {
+		return ErrOperationNotExists
+	}
+	for _, result := range results {
+		for i := range operation.Results {
+			if operation.Results[i].ShortURL == result.ShortURL {
+				operation.Results[i] = result
+			}
+		}
+	}
+	operation.UpdatedAt = time.Now().UTC()
+	m.DeleteOperations[operationID] = operation
+	return nil
+}
+
+// snapshot of all delete operations
+func (m *InMemoryStore) allDeleteOperations() []models.DeleteOperation {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	operations := make([]models.DeleteOperation, 0, len(m.DeleteOperations))
+	for _, operation := range m.DeleteOperations {
+		operation.Results = append([]models.DeleteResult(nil), operation.Results...)
+		operations = append(operations, operation)
+	}
+	return operations
+}
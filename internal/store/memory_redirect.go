@@ -0,0 +1,55 @@
+package store
+
+import (
+	"context"
+
+	"github.com/hollgett/shortener.git/internal/models"
+)
+
+func (m *InMemoryStore) GetRedirect(_ context.Context, shortURL string) (models.Redirect, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	URL, ok := m.URLs[shortURL]
+	if !ok {
+		return models.Redirect{}, ErrIsNotExists
+	}
+	if URL.DeletedFlag {
+		return models.Redirect{}, ErrURLDeleted
+	}
+	if URL.DisabledFlag {
+		return models.Redirect{}, ErrURLDisabled
+	}
+	return models.Redirect{
+		OriginalURL:    URL.OriginalURL,
+		Title:          URL.Title,
+		CreatedAt:      URL.CreatedAt,
+		RedirectPolicy: URL.RedirectPolicy,
+	}, nil
+}
+
+func (m *InMemoryStore) SetURLRedirect(shortURL string, policy models.RedirectPolicy) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	URL, ok := m.URLs[shortURL]
+	if !ok {
+		return ErrIsNotExists
+	}
+	URL.RedirectPolicy = policy
+	m.putURL(URL)
+	return nil
+}
+
+func (m *InMemoryStore) SetURLTitle(shortURL, title string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	URL, ok := m.URLs[shortURL]
+	if !ok {
+		return ErrIsNotExists
+	}
+	URL.Title = title
+	m.putURL(URL)
+	return nil
+}
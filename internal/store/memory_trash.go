@@ -0,0 +1,63 @@
+package store
+
+import (
+	"sort"
+	"time"
+
+	"github.com/hollgett/shortener.git/internal/models"
+)
+
+func (m *InMemoryStore) GetDeletedUserURLs(userID string) ([]models.DeletedURLResponse, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	URLs := make([]models.DeletedURLResponse, 0)
+	for _, URL := range m.URLs {
+		if URL.UserID != userID || len(URL.WorkspaceID) != 0 || !URL.DeletedFlag {
+			continue
+		}
+		deleted := models.DeletedURLResponse{
+			ShortURL:    URL.ShortURL,
+			OriginalURL: URL.OriginalURL,
+		}
+		if URL.DeletedAt != nil {
+			deleted.DeletedAt = *URL.DeletedAt
+		}
+		URLs = append(URLs, deleted)
+	}
+	sort.Slice(URLs, func(i, j int) bool { return URLs[i].DeletedAt.After(URLs[j].DeletedAt) })
+	return URLs, nil
+}
+
+func (m *InMemoryStore) RestoreURLs(userID string, shortURLs []string) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	restored := make([]string, 0, len(shortURLs))
+	for _, short := range shortURLs {
+		URL, ok := m.URLs[short]
+		if !ok || URL.UserID != userID || len(URL.WorkspaceID) != 0 || !URL.DeletedFlag {
+			continue
+		}
+		URL.DeletedFlag = false
+		URL.DeletedAt = nil
+		m.putURL(URL)
+		restored = append(restored, short)
+	}
+	return restored, nil
+}
+
+func (m *InMemoryStore) PurgeDeletedURLs(deletedBefore time.Time) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	purged := 0
+	for _, URL := range m.URLs {
+		if !URL.DeletedFlag || (URL.DeletedAt != nil && !URL.DeletedAt.Before(deletedBefore)) {
+			continue
+		}
+		m.removeURL(URL)
+		purged++
+	}
+	return purged, nil
+}
@@ -0,0 +1,153 @@
+package store
+
+import (
+	"github.com/hollgett/shortener.git/internal/models"
+)
+
+func (m *InMemoryStore) CreateUser(user models.User) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.Logins[user.Login]; ok {
+		return ErrUserExists
+	}
+	if _, ok := m.Users[user.ID]; ok {
+		return ErrUserExists
+	}
+	m.Users[user.ID] = user
+	m.Logins[user.Login] = user.ID
+	return nil
+}
+
+func (m *InMemoryStore) GetUserByLogin(login string) (models.User, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	userID, ok := m.Logins[login]
+	if !ok {
+		return models.User{}, ErrUserNotExists
+	}
+	return m.Users[userID], nil
+}
+
+func (m *InMemoryStore) GetUserByID(userID string) (models.User, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	user, ok := m.Users[userID]
+	if !ok {
+		return models.User{}, ErrUserNotExists
+	}
+	return user, nil
+}
+
+func (m *InMemoryStore) ReassignUserURLs(fromUserID, toUserID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, URL := range m.URLs {
+		if URL.UserID == fromUserID {
+			m.unindexURL(URL)
+			URL.UserID = toUserID
+			m.putURL(URL)
+			m.indexURL(URL)
+		}
+	}
+	return nil
+}
+
+func (m *InMemoryStore) CreateSession(session models.Session) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.Sessions[session.ID] = session
+	return nil
+}
+
+func (m *InMemoryStore) GetSession(sessionID string) (models.Session, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	session, ok := m.Sessions[sessionID]
+	if !ok {
+		return models.Session{}, ErrSessionNotExists
+	}
+	return session, nil
+}
+
+func (m *InMemoryStore) RevokeSession(sessionID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	session, ok := m.Sessions[sessionID]
+	if !ok {
+		return ErrSessionNotExists
+	}
+	session.Revoked = true
+	m.Sessions[sessionID] = session
+	return nil
+}
+
+func (m *InMemoryStore) RevokeUserSessions(userID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for id, session := range m.Sessions {
+		if session.UserID == userID {
+			session.Revoked = true
+			m.Sessions[id] = session
+		}
+	}
+	return nil
+}
+
+func (m *InMemoryStore) CreateUserIdentity(identity models.UserIdentity) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := identityKey{issuer: identity.Issuer, subject: identity.Subject}
+	if _, ok := m.Identities[key]; ok {
+		return ErrIdentityExists
+	}
+	m.Identities[key] = identity
+	return nil
+}
+
+func (m *InMemoryStore) GetUserIdentity(issuer, subject string) (models.UserIdentity, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	identity, ok := m.Identities[identityKey{issuer: issuer, subject: subject}]
+	if !ok {
+		return models.UserIdentity{}, ErrIdentityNotExists
+	}
+	return identity, nil
+}
+
+// snapshot of external identities
+func (m *InMemoryStore) allIdentities() []models.UserIdentity {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	identities := make([]models.UserIdentity, 0, len(m.Identities))
+	for _, identity := range m.Identities {
+		identities = append(identities, identity)
+	}
+	return identities
+}
+
+// snapshot of users and sessions
+func (m *InMemoryStore) allUsers() ([]models.User, []models.Session) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	users := make([]models.User, 0, len(m.Users))
+	for _, user := range m.Users {
+		users = append(users, user)
+	}
+	sessions := make([]models.Session, 0, len(m.Sessions))
+	for _, session := range m.Sessions {
+		sessions = append(sessions, session)
+	}
+	return users, sessions
+}
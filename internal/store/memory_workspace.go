@@ -0,0 +1,185 @@
+package store
+
+import (
+	"time"
+
+	"github.com/hollgett/shortener.git/internal/models"
+)
+
+func (m *InMemoryStore) CreateWorkspace(workspace models.Workspace, owner models.WorkspaceMember) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.Workspaces[workspace.ID] = workspace
+	m.Members[workspace.ID] = map[string]models.WorkspaceMember{owner.UserID: owner}
+	return nil
+}
+
+func (m *InMemoryStore) GetWorkspace(workspaceID string) (models.Workspace, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	workspace, ok := m.Workspaces[workspaceID]
+	if !ok {
+		return models.Workspace{}, ErrWorkspaceNotExists
+	}
+	return workspace, nil
+}
+
+func (m *InMemoryStore) GetUserWorkspaces(userID string) ([]models.WorkspaceResponse, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	workspaces := make([]models.WorkspaceResponse, 0)
+	for workspaceID, members := range m.Members {
+		member, ok := members[userID]
+		if !ok {
+			continue
+		}
+		workspace := m.Workspaces[workspaceID]
+		workspaces = append(workspaces, models.WorkspaceResponse{
+			ID:        workspace.ID,
+			Name:      workspace.Name,
+			Role:      member.Role,
+			CreatedAt: workspace.CreatedAt,
+		})
+	}
+	return workspaces, nil
+}
+
+func (m *InMemoryStore) GetWorkspaceMember(workspaceID, userID string) (models.WorkspaceMember, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	member, ok := m.Members[workspaceID][userID]
+	if !ok {
+		return models.WorkspaceMember{}, ErrMemberNotExists
+	}
+	return member, nil
+}
+
+func (m *InMemoryStore) GetWorkspaceMembers(workspaceID string) ([]models.WorkspaceMember, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if _, ok := m.Workspaces[workspaceID]; !ok {
+		return nil, ErrWorkspaceNotExists
+	}
+	members := make([]models.WorkspaceMember, 0, len(m.Members[workspaceID]))
+	for _, member := range m.Members[workspaceID] {
+		members = append(members, member)
+	}
+	return members, nil
+}
+
+func (m *InMemoryStore) SaveWorkspaceMember(member models.WorkspaceMember) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	members, ok := m.Members[member.WorkspaceID]
+	if !ok {
+		return ErrWorkspaceNotExists
+	}
+	if existing, ok := members[member.UserID]; ok {
+		member.CreatedAt = existing.CreatedAt
+	}
+	members[member.UserID] = member
+	return nil
+}
+
+func (m *InMemoryStore) DeleteWorkspaceMember(workspaceID, userID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.Members[workspaceID][userID]; !ok {
+		return ErrMemberNotExists
+	}
+	delete(m.Members[workspaceID], userID)
+	return nil
+}
+
+func (m *InMemoryStore) GetWorkspaceURLs(workspaceID string) ([]models.URLResponse, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	URLs := make([]models.URLResponse, 0)
+	for _, URL := range m.URLs {
+		if URL.WorkspaceID == workspaceID && !URL.DeletedFlag {
+			URLs = append(URLs, models.URLResponse{
+				ShortURL:    URL.ShortURL,
+				OriginalURL: URL.OriginalURL,
+			})
+		}
+	}
+	return URLs, nil
+}
+
+func (m *InMemoryStore) MoveURLToWorkspace(userID, shortURL, workspaceID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	URL, ok := m.URLs[shortURL]
+	if !ok || URL.UserID != userID || URL.DeletedFlag {
+		return ErrIsNotExists
+	}
+	URL.WorkspaceID = workspaceID
+	m.putURL(URL)
+	return nil
+}
+
+func (m *InMemoryStore) UpdateWorkspaceURL(workspaceID, shortURL, originalURL string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	URL, ok := m.URLs[shortURL]
+	if !ok || URL.WorkspaceID != workspaceID || URL.DeletedFlag {
+		return ErrIsNotExists
+	}
+	if URL.OriginalURL == originalURL {
+		return nil
+	}
+	updated := URL
+	updated.OriginalURL = originalURL
+	if _, ok := m.OriginalURLs[m.dedup.key(updated)]; ok {
+		return ErrShortExists
+	}
+	m.unindexURL(URL)
+	m.putURL(updated)
+	m.indexURL(updated)
+	return nil
+}
+
+func (m *InMemoryStore) DeleteWorkspaceURLs(workspaceID string, shortURLs []string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now().UTC()
+	for _, short := range shortURLs {
+		URL, ok := m.URLs[short]
+		if !ok || URL.WorkspaceID != workspaceID || URL.DeletedFlag {
+			continue
+		}
+		URL.DeletedFlag = true
+		URL.DeletedAt = &now
+		m.putURL(URL)
+	}
+	return nil
+}
+
+// snapshot of workspaces and members
+func (m *InMemoryStore) allWorkspaces() ([]models.Workspace, []models.WorkspaceMember) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	workspaces := make([]models.Workspace, 0, len(m.Workspaces))
+	for _, workspace := range m.Workspaces {
+		workspaces = append(workspaces, workspace)
+	}
+	members := make([]models.WorkspaceMember, 0)
+	for _, workspaceMembers := range m.Members {
+		for _, member := range workspaceMembers {
+			members = append(members, member)
+		}
+	}
+	return workspaces, members
+}
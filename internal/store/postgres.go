@@ -1,10 +1,11 @@
 package store
 
 import (
-	"database/sql"
+	"context"
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/golang-migrate/migrate/v4"
 	"github.com/golang-migrate/migrate/v4/database/postgres"
@@ -12,29 +13,93 @@ import (
 	"github.com/hollgett/shortener.git/internal/logger"
 	"github.com/hollgett/shortener.git/internal/models"
 	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
-	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5/stdlib"
 )
 
+// PoolConfig tuning of PostgreSQL connection pool, zero values keep pgx defaults
+type PoolConfig struct {
+	MaxConns          int32
+	MinConns          int32
+	MaxConnLifetime   time.Duration
+	MaxConnIdleTime   time.Duration
+	HealthCheckPeriod time.Duration
+	// capacity of prepared statements cache per connection, 0 disables caching and statements are described on every exec
+	StatementCache int
+	// deadline of single query, 0 is without deadline
+	QueryTimeout time.Duration
+}
+
 type PostgreSQLStore struct {
-	logger             *logger.Logger
-	DB                 *sql.DB
-	insertStmt         *sql.Stmt
-	selectShortStmt    *sql.Stmt
-	selectOriginalStmt *sql.Stmt
-	selectUserURLsStmt *sql.Stmt
+	logger       *logger.Logger
+	Pool         *pgxpool.Pool
+	queryTimeout time.Duration
+	dedup        DedupScope
+}
+
+// build pool config from DSN with tuning options
+func newPoolConfig(DSN string, cfg PoolConfig) (*pgxpool.Config, error) {
+	poolCfg, err := pgxpool.ParseConfig(DSN)
+	if err != nil {
+		return nil, fmt.Errorf("failed parse DSN: %w", err)
+	}
+	if cfg.MaxConns > 0 {
+		poolCfg.MaxConns = cfg.MaxConns
+	}
+	if cfg.MinConns > 0 {
+		poolCfg.MinConns = cfg.MinConns
+	}
+	if cfg.MaxConnLifetime > 0 {
+		poolCfg.MaxConnLifetime = cfg.MaxConnLifetime
+	}
+	if cfg.MaxConnIdleTime > 0 {
+		poolCfg.MaxConnIdleTime = cfg.MaxConnIdleTime
+	}
+	if cfg.HealthCheckPeriod > 0 {
+		poolCfg.HealthCheckPeriod = cfg.HealthCheckPeriod
+	}
+	if cfg.StatementCache > 0 {
+		poolCfg.ConnConfig.DefaultQueryExecMode = pgx.QueryExecModeCacheStatement
+		poolCfg.ConnConfig.StatementCacheCapacity = cfg.StatementCache
+	} else {
+		poolCfg.ConnConfig.DefaultQueryExecMode = pgx.QueryExecModeDescribeExec
+	}
+	return poolCfg, nil
 }
 
-// try open connection and ping server
-func newConn(DSN string) (*sql.DB, error) {
-	db, err := sql.Open("pgx", DSN)
+// try open pool and ping server
+func newPool(DSN string, cfg PoolConfig) (*pgxpool.Pool, error) {
+	poolCfg, err := newPoolConfig(DSN, cfg)
+	if err != nil {
+		return nil, err
+	}
+	pool, err := pgxpool.NewWithConfig(context.Background(), poolCfg)
 	if err != nil {
-		return nil, fmt.Errorf("failed open connection to database: %w", err)
+		return nil, fmt.Errorf("failed open connection pool to database: %w", err)
 	}
-	if err := db.Ping(); err != nil {
+	if err := pool.Ping(context.Background()); err != nil {
+		pool.Close()
 		return nil, fmt.Errorf("failed ping database error: %w", err)
 	}
-	return db, nil
+	return pool, nil
+}
+
+// acquire dedicated connection, it is taken from pool and never returned so session state like locks and listen is dropped on close
+func (p *PostgreSQLStore) dedicatedConn(ctx context.Context) (*pgx.Conn, error) {
+	conn, err := p.Pool.Acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return conn.Hijack(), nil
+}
+
+// close dedicated connection
+func discardConn(conn *pgx.Conn) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	conn.Close(ctx)
 }
 
 func getPGError(err error) *pgconn.PgError {
@@ -45,46 +110,45 @@ func getPGError(err error) *pgconn.PgError {
 	return nil
 }
 
-// NewPostgreSQLStore create new connection to PostgreSQL and return error if newConn have problem with open connection and ping database.
-func NewPostgreSQLStore(logger *logger.Logger, DSN string) (*PostgreSQLStore, error) {
-	postgreSQLStore := PostgreSQLStore{
-		logger: logger,
-	}
-
-	db, err := newConn(DSN)
+// NewPostgreSQLStore create new connection pool to PostgreSQL and return error if newPool have problem with open connection and ping database.
+func NewPostgreSQLStore(logger *logger.Logger, DSN string, cfg PoolConfig, dedup DedupScope) (*PostgreSQLStore, error) {
+	postgreSQLStore, err := OpenPostgreSQLStore(logger, DSN, cfg, dedup)
 	if err != nil {
-		return nil, fmt.Errorf("new conn error: %w", err)
+		return nil, err
 	}
-	postgreSQLStore.DB = db
 
 	if err := postgreSQLStore.runMigrations(); err != nil {
+		postgreSQLStore.Close()
 		return nil, fmt.Errorf("failed run migrations: %w", err)
 	}
-
-	STMTs := []struct {
-		name     string
-		query    string
-		addrStmt **sql.Stmt
-	}{
-		{"insert url", InsertReq, &postgreSQLStore.insertStmt},
-		{"select original", SelectOriginalReq, &postgreSQLStore.selectOriginalStmt},
-		{"select short", selectShortReq, &postgreSQLStore.selectShortStmt},
-		{"select user URLs", SelectUserURLsReq, &postgreSQLStore.selectUserURLsStmt},
-	}
-	for _, stmt := range STMTs {
-		prep, err := postgreSQLStore.DB.Prepare(stmt.query)
-		if err != nil {
-			err := postgreSQLStore.Close()
-			return nil, errors.Join(fmt.Errorf("failed create stmt %s: %w", stmt.name, err), err)
-		}
-		*stmt.addrStmt = prep
+	if err := postgreSQLStore.rekeyDedup(); err != nil {
+		postgreSQLStore.Close()
+		return nil, fmt.Errorf("failed rebuild dedup keys: %w", err)
 	}
 
-	return &postgreSQLStore, nil
+	return postgreSQLStore, nil
+}
+
+// OpenPostgreSQLStore create connection pool without running migrations, database is not changed by open.
+func OpenPostgreSQLStore(logger *logger.Logger, DSN string, cfg PoolConfig, dedup DedupScope) (*PostgreSQLStore, error) {
+	pool, err := newPool(DSN, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("new pool error: %w", err)
+	}
+	return &PostgreSQLStore{
+		logger:       logger,
+		Pool:         pool,
+		queryTimeout: cfg.QueryTimeout,
+		dedup:        dedup,
+	}, nil
 }
 
 func (p *PostgreSQLStore) runMigrations() error {
-	driver, err := postgres.WithInstance(p.DB, &postgres.Config{})
+	// migrate works over database/sql, db shares connections of pool and closing it keeps pool open
+	db := stdlib.OpenDBFromPool(p.Pool)
+	defer db.Close()
+
+	driver, err := postgres.WithInstance(db, &postgres.Config{})
 	if err != nil {
 		return fmt.Errorf("failed create driver migrations: %w", err)
 	}
@@ -100,37 +164,110 @@ func (p *PostgreSQLStore) runMigrations() error {
 	return nil
 }
 
-func (p *PostgreSQLStore) closeStmt() error {
-	var errs []error
-	if err := p.insertStmt.Close(); err != nil {
-		errs = append(errs, fmt.Errorf("failed close %s stmt: %w", "insertReq", err))
+// context of single query limited by query timeout
+func (p *PostgreSQLStore) context() (context.Context, context.CancelFunc) {
+	return p.contextFrom(context.Background())
+}
+
+// context of single query of request, query is canceled with request or by query timeout
+func (p *PostgreSQLStore) contextFrom(parent context.Context) (context.Context, context.CancelFunc) {
+	if p.queryTimeout <= 0 {
+		return context.WithCancel(parent)
 	}
+	return context.WithTimeout(parent, p.queryTimeout)
+}
+
+func (p *PostgreSQLStore) exec(query string, args ...any) (pgconn.CommandTag, error) {
+	return p.execContext(context.Background(), query, args...)
+}
+
+func (p *PostgreSQLStore) execContext(ctx context.Context, query string, args ...any) (pgconn.CommandTag, error) {
+	ctx, cancel := p.contextFrom(ctx)
+	defer cancel()
+	return p.Pool.Exec(ctx, query, args...)
+}
 
-	if err := p.selectOriginalStmt.Close(); err != nil {
-		errs = append(errs, fmt.Errorf("failed close %s stmt: %w", "selectOriginalReq", err))
+// query rows, context of query is canceled on rows close
+func (p *PostgreSQLStore) query(query string, args ...any) (pgx.Rows, error) {
+	ctx, cancel := p.context()
+	rows, err := p.Pool.Query(ctx, query, args...)
+	if err != nil {
+		cancel()
+		return nil, err
 	}
+	return &cancelRows{Rows: rows, cancel: cancel}, nil
+}
 
-	if err := p.selectShortStmt.Close(); err != nil {
-		errs = append(errs, fmt.Errorf("failed close %s stmt: %w", "selectShortStmt", err))
+// query single row, context of query is canceled after scan
+func (p *PostgreSQLStore) queryRow(query string, args ...any) pgx.Row {
+	return p.queryRowContext(context.Background(), query, args...)
+}
+
+func (p *PostgreSQLStore) queryRowContext(ctx context.Context, query string, args ...any) pgx.Row {
+	ctx, cancel := p.contextFrom(ctx)
+	return &cancelRow{row: p.Pool.QueryRow(ctx, query, args...), cancel: cancel}
+}
+
+// inTx run fn in transaction, commit if fn succeeded and rollback otherwise
+func (p *PostgreSQLStore) inTx(fn func(ctx context.Context, tx pgx.Tx) error) error {
+	return p.inTxContext(context.Background(), fn)
+}
+
+func (p *PostgreSQLStore) inTxContext(ctx context.Context, fn func(ctx context.Context, tx pgx.Tx) error) error {
+	ctx, cancel := p.contextFrom(ctx)
+	defer cancel()
+
+	tx, err := p.Pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed begin transaction: %w", err)
 	}
+	defer tx.Rollback(ctx)
 
-	if len(errs) != 0 {
-		return errors.Join(errs...)
+	if err := fn(ctx, tx); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed commit transaction: %w", err)
 	}
 	return nil
 }
 
+type cancelRows struct {
+	pgx.Rows
+	cancel context.CancelFunc
+}
+
+func (r *cancelRows) Close() {
+	r.Rows.Close()
+	r.cancel()
+}
+
+type cancelRow struct {
+	row    pgx.Row
+	cancel context.CancelFunc
+}
+
+func (r *cancelRow) Scan(dest ...any) error {
+	defer r.cancel()
+	return r.row.Scan(dest...)
+}
+
 func (p *PostgreSQLStore) Ping() error {
-	return p.DB.Ping()
+	ctx, cancel := p.context()
+	defer cancel()
+	return p.Pool.Ping(ctx)
 }
 
-func (p *PostgreSQLStore) SaveShortURL(URL models.ShortenerURL) (string, error) {
-	_, err := p.insertStmt.Query(URL.OriginalURL, URL.ShortURL, URL.UserID)
+func (p *PostgreSQLStore) SaveShortURL(ctx context.Context, URL models.ShortenerURL) (string, error) {
+	key := p.dedup.key(URL)
+	_, err := p.execContext(ctx, InsertReq, URL.OriginalURL, URL.ShortURL, URL.UserID, URL.WorkspaceID, key,
+		URL.RedirectStatus, URL.CacheControl, URL.Tracked, URL.Title, URL.CreatedAt)
 	if err == nil {
 		return "", nil
-	} else if pgErr := getPGError(err); pgErr != nil && pgErr.Code == pgerrcode.UniqueViolation {
+	} else if pgErr := getPGError(err); pgErr != nil && pgErr.Code == pgerrcode.UniqueViolation && key != "" {
 		var shortExists string
-		if err := p.selectShortStmt.QueryRow(URL.OriginalURL).Scan(&shortExists); err != nil {
+		if err := p.queryRowContext(ctx, selectShortReq, key).Scan(&shortExists); err != nil {
 			return "", fmt.Errorf("failed select short link: %w", err)
 		}
 		return shortExists, ErrShortExists
@@ -138,62 +275,89 @@ func (p *PostgreSQLStore) SaveShortURL(URL models.ShortenerURL) (string, error)
 	return "", fmt.Errorf("failed insert exec: %w", err)
 }
 
-func (p *PostgreSQLStore) SaveShortURLs(URLs []models.ShortenerURL) ([]models.ShortenerURL, error) {
-	// create transaction
-	tx, err := p.DB.Begin()
-	if err != nil {
-		return nil, fmt.Errorf("failed begin transaction: %w", err)
+func (p *PostgreSQLStore) SaveShortURLs(ctx context.Context, URLs []models.ShortenerURL) ([]models.ShortenerURL, error) {
+	originals := make([]string, len(URLs))
+	shorts := make([]string, len(URLs))
+	userIDs := make([]string, len(URLs))
+	workspaceIDs := make([]string, len(URLs))
+	keys := make([]string, len(URLs))
+	redirectStatuses := make([]int32, len(URLs))
+	cacheControls := make([]string, len(URLs))
+	tracked := make([]bool, len(URLs))
+	titles := make([]string, len(URLs))
+	createdAt := make([]*time.Time, len(URLs))
+	for i, v := range URLs {
+		originals[i], shorts[i], userIDs[i], workspaceIDs[i] = v.OriginalURL, v.ShortURL, v.UserID, v.WorkspaceID
+		keys[i] = p.dedup.key(v)
+		redirectStatuses[i], cacheControls[i], tracked[i] = int32(v.RedirectStatus), v.CacheControl, v.Tracked
+		titles[i], createdAt[i] = v.Title, v.CreatedAt
 	}
-	defer tx.Rollback()
 
-	// set prepare to transaction
-	insertTx, err := tx.Prepare(InsertReq)
+	// short by dedup key, inserted or existing before, links without key are mapped by own short
+	shortByKey := make(map[string]string, len(URLs))
+	err := p.inTxContext(ctx, func(ctx context.Context, tx pgx.Tx) error {
+		if err := scanShorts(ctx, tx, shortByKey, insertURLsReq, originals, shorts, userIDs, workspaceIDs, keys,
+			redirectStatuses, cacheControls, tracked, titles, createdAt); err != nil {
+			return fmt.Errorf("failed insert urls: %w", err)
+		}
+		var missing []string
+		for _, key := range keys {
+			if _, ok := shortByKey[key]; !ok && key != "" {
+				missing = append(missing, key)
+			}
+		}
+		if len(missing) == 0 {
+			return nil
+		}
+		if err := scanShorts(ctx, tx, shortByKey, selectShortsReq, missing); err != nil {
+			return fmt.Errorf("failed select existing urls: %w", err)
+		}
+		return nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed set prepare insert to transaction: %w", err)
+		return nil, err
 	}
 
-	// begin transaction request
-	for _, v := range URLs {
-		// //create save point transaction
-		// if _, err := tx.Exec(fmt.Sprintf("SAVEPOINT sp%s", i)); err != nil {
-		// 	tx.Rollback()
-		// 	return nil, fmt.Errorf("failed create save point: %w", err)
-		// }
-
-		// insert to database
-		if _, err := insertTx.Exec(v.OriginalURL, v.ShortURL, v.UserID); err != nil {
-			// tx.Exec(fmt.Sprintf("ROLLBACK TO SAVEPOINT sp%s", i))
-			return nil, fmt.Errorf("failed insert original: %s, short: %s: %w", v.OriginalURL, v.ShortURL, err)
+	saved := make([]models.ShortenerURL, len(URLs))
+	for i, v := range URLs {
+		key := keys[i]
+		if key == "" {
+			key = v.ShortURL
 		}
+		short, ok := shortByKey[key]
+		if !ok {
+			return nil, fmt.Errorf("original %s is not saved", v.OriginalURL)
+		}
+		if short != v.ShortURL {
+			v.ShortURL = short
+			v.Conflict = true
+		}
+		saved[i] = v
 	}
-
-	if err := tx.Commit(); err != nil {
-		return nil, fmt.Errorf("failed commit transaction: %w", err)
-	}
-
-	return URLs, nil
+	return saved, nil
 }
 
-func (p *PostgreSQLStore) GetOriginalURL(ShortLink string) (string, error) {
-	row := p.selectOriginalStmt.QueryRow(ShortLink)
-
-	var originalURL string
-	var is_deleted bool
-	if err := row.Scan(&originalURL, &is_deleted); err == sql.ErrNoRows {
-		return "", ErrIsNotExists
-	} else if err != nil {
-		return "", fmt.Errorf("failed scan row: %w", err)
+// scan pairs of key and short returned by query, present pairs are kept
+func scanShorts(ctx context.Context, tx pgx.Tx, shortByKey map[string]string, query string, args ...any) error {
+	rows, err := tx.Query(ctx, query, args...)
+	if err != nil {
+		return err
 	}
-
-	if is_deleted {
-		return "", ErrURLDeleted
+	defer rows.Close()
+	for rows.Next() {
+		var key, short string
+		if err := rows.Scan(&key, &short); err != nil {
+			return fmt.Errorf("failed scan rows: %w", err)
+		}
+		if _, ok := shortByKey[key]; !ok {
+			shortByKey[key] = short
+		}
 	}
-
-	return originalURL, nil
+	return rows.Err()
 }
 
 func (p *PostgreSQLStore) GetUserURLs(userID string) ([]models.URLResponse, error) {
-	rows, err := p.selectUserURLsStmt.Query(userID)
+	rows, err := p.query(SelectUserURLsReq, userID)
 	if err != nil {
 		return nil, fmt.Errorf("failed query: %w", err)
 	}
@@ -224,7 +388,7 @@ func (p *PostgreSQLStore) DeleteURLs(URLs []models.DeleteURL) error {
 	args := make([]interface{}, 0)
 	query.WriteString(`
 	UPDATE shortener_urls AS s
-	SET is_deleted = TRUE
+	SET is_deleted = TRUE, deleted_at = now()
 	FROM (VALUES`)
 
 	for i, v := range URLs {
@@ -237,16 +401,37 @@ func (p *PostgreSQLStore) DeleteURLs(URLs []models.DeleteURL) error {
 
 	query.WriteString(`) AS tmp(user_id, short)
 	WHERE s.user_id = tmp.user_id
-  	AND s.short = tmp.short;`)
+  	AND s.short = tmp.short
+	AND s.workspace_id IS NULL
+	AND s.is_deleted = FALSE;`)
 
-	if _, err := p.DB.Exec(query.String(), args...); err != nil {
+	if _, err := p.exec(query.String(), args...); err != nil {
 		return fmt.Errorf("failed batch delete urls: %w", err)
 	}
 	return nil
 }
 
+func (p *PostgreSQLStore) GetStats() (models.Stats, error) {
+	var stats models.Stats
+	if err := p.queryRow(selectStatsReq).Scan(&stats.URLs, &stats.Users, &stats.Deleted); err != nil {
+		return models.Stats{}, fmt.Errorf("failed scan stats: %w", err)
+	}
+
+	pool := p.Pool.Stat()
+	stats.Pool = &models.PoolStats{
+		TotalConns:           pool.TotalConns(),
+		AcquiredConns:        pool.AcquiredConns(),
+		IdleConns:            pool.IdleConns(),
+		MaxConns:             pool.MaxConns(),
+		AcquireCount:         pool.AcquireCount(),
+		EmptyAcquireCount:    pool.EmptyAcquireCount(),
+		CanceledAcquireCount: pool.CanceledAcquireCount(),
+		AcquireDuration:      pool.AcquireDuration().String(),
+	}
+	return stats, nil
+}
+
 func (p *PostgreSQLStore) Close() error {
-	errStmt := p.closeStmt()
-	errDB := p.DB.Close()
-	return errors.Join(errStmt, errDB)
+	p.Pool.Close()
+	return nil
 }
@@ -0,0 +1,112 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/hollgett/shortener.git/internal/models"
+	"github.com/jackc/pgx/v5"
+)
+
+func (p *PostgreSQLStore) SearchURLs(filter models.AdminURLFilter) ([]models.AdminURL, error) {
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = math.MaxInt32
+	}
+	return p.queryAdminURLs(searchURLsReq, filter.ShortURL, filter.OriginalURL, filter.UserID, limit)
+}
+
+func (p *PostgreSQLStore) GetURLs(shortURLs []string) ([]models.AdminURL, error) {
+	return p.queryAdminURLs(selectURLsReq, shortURLs)
+}
+
+func (p *PostgreSQLStore) queryAdminURLs(query string, args ...any) ([]models.AdminURL, error) {
+	rows, err := p.query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed query: %w", err)
+	}
+	defer rows.Close()
+
+	URLs := make([]models.AdminURL, 0)
+	for rows.Next() {
+		URL, err := scanAdminURL(rows)
+		if err != nil {
+			return nil, err
+		}
+		URLs = append(URLs, URL)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+	return URLs, nil
+}
+
+func (p *PostgreSQLStore) GetURL(shortURL string) (models.AdminURL, error) {
+	URL, err := scanAdminURL(p.queryRow(selectURLReq, shortURL))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.AdminURL{}, ErrIsNotExists
+	}
+	return URL, err
+}
+
+func (p *PostgreSQLStore) SetURLDisabled(shortURL string, disabled bool) error {
+	return p.execAffectedURL(setURLDisabledReq, shortURL, disabled)
+}
+
+func (p *PostgreSQLStore) ReassignURL(shortURL, userID string) error {
+	return p.execAffectedURL(reassignURLReq, shortURL, userID)
+}
+
+func (p *PostgreSQLStore) HardDeleteURL(shortURL string) error {
+	return p.execAffectedURL(hardDeleteURLReq, shortURL)
+}
+
+func (p *PostgreSQLStore) SaveAuditRecord(record models.AuditRecord) error {
+	_, err := p.exec(insertAuditReq, record.ID, record.ActorID, record.RequestID, record.IP, record.Action, record.ShortURL,
+		record.Before, record.After, record.Details, record.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed insert audit record: %w", err)
+	}
+	return nil
+}
+
+func (p *PostgreSQLStore) GetAuditRecords(filter models.AuditFilter) ([]models.AuditRecord, error) {
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = math.MaxInt32
+	}
+	from := sql.NullTime{Time: filter.From, Valid: !filter.From.IsZero()}
+	to := sql.NullTime{Time: filter.To, Valid: !filter.To.IsZero()}
+	rows, err := p.query(selectAuditReq, from, to, filter.ActorID, filter.ShortURL, filter.Action, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed query: %w", err)
+	}
+	defer rows.Close()
+
+	records := make([]models.AuditRecord, 0)
+	for rows.Next() {
+		var record models.AuditRecord
+		err := rows.Scan(&record.ID, &record.ActorID, &record.RequestID, &record.IP, &record.Action, &record.ShortURL,
+			&record.Before, &record.After, &record.Details, &record.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed scan rows: %w", err)
+		}
+		records = append(records, record)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+	return records, nil
+}
+
+func scanAdminURL(row rowScanner) (models.AdminURL, error) {
+	var URL models.AdminURL
+	err := row.Scan(&URL.ShortURL, &URL.OriginalURL, &URL.UserID, &URL.WorkspaceID, &URL.Deleted, &URL.Disabled,
+		&URL.Title, &URL.CreatedAt, &URL.RedirectStatus, &URL.CacheControl, &URL.Tracked)
+	if err != nil {
+		return models.AdminURL{}, fmt.Errorf("failed scan url: %w", err)
+	}
+	return URL, nil
+}
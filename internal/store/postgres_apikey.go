@@ -0,0 +1,104 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hollgett/shortener.git/internal/models"
+	"github.com/jackc/pgx/v5"
+)
+
+// scopes are stored as comma separated string
+const scopesSeparator = ","
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanAPIKey(row rowScanner) (models.APIKey, error) {
+	var (
+		key        models.APIKey
+		scopes     string
+		lastUsedAt sql.NullTime
+		expiresAt  sql.NullTime
+	)
+	if err := row.Scan(&key.ID, &key.UserID, &key.Name, &key.Hash, &scopes,
+		&key.CreatedAt, &lastUsedAt, &expiresAt, &key.Revoked); err != nil {
+		return models.APIKey{}, err
+	}
+	if len(scopes) != 0 {
+		key.Scopes = strings.Split(scopes, scopesSeparator)
+	}
+	if lastUsedAt.Valid {
+		key.LastUsedAt = &lastUsedAt.Time
+	}
+	if expiresAt.Valid {
+		key.ExpiresAt = &expiresAt.Time
+	}
+	return key, nil
+}
+
+func (p *PostgreSQLStore) CreateAPIKey(key models.APIKey) error {
+	var expiresAt sql.NullTime
+	if key.ExpiresAt != nil {
+		expiresAt = sql.NullTime{Time: *key.ExpiresAt, Valid: true}
+	}
+	_, err := p.exec(insertAPIKeyReq, key.ID, key.UserID, key.Name, key.Hash,
+		strings.Join(key.Scopes, scopesSeparator), key.CreatedAt, expiresAt)
+	if err != nil {
+		return fmt.Errorf("failed insert api key: %w", err)
+	}
+	return nil
+}
+
+func (p *PostgreSQLStore) GetAPIKey(keyID string) (models.APIKey, error) {
+	key, err := scanAPIKey(p.queryRow(selectAPIKeyReq, keyID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.APIKey{}, ErrAPIKeyNotExists
+	} else if err != nil {
+		return models.APIKey{}, fmt.Errorf("failed scan api key: %w", err)
+	}
+	return key, nil
+}
+
+func (p *PostgreSQLStore) GetUserAPIKeys(userID string) ([]models.APIKey, error) {
+	rows, err := p.query(selectUserAPIKeysReq, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed query: %w", err)
+	}
+	defer rows.Close()
+
+	keys := make([]models.APIKey, 0)
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed scan rows: %w", err)
+		}
+		keys = append(keys, key)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+	return keys, nil
+}
+
+func (p *PostgreSQLStore) RevokeAPIKey(userID, keyID string) error {
+	res, err := p.exec(revokeAPIKeyReq, userID, keyID)
+	if err != nil {
+		return fmt.Errorf("failed revoke api key: %w", err)
+	}
+	if res.RowsAffected() == 0 {
+		return ErrAPIKeyNotExists
+	}
+	return nil
+}
+
+func (p *PostgreSQLStore) TouchAPIKey(keyID string, usedAt time.Time) error {
+	if _, err := p.exec(touchAPIKeyReq, keyID, usedAt); err != nil {
+		return fmt.Errorf("failed update api key last used: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package store
+
+import (
+	"context"
+	"fmt"
+
+	"go.uber.org/zap"
+)
+
+// name of setting with scope which dedup keys are built for
+const dedupScopeSetting = "dedup_scope"
+
+// rebuild dedup keys when scope differs from scope of stored keys, same as memory store does on load.
+//
+// all links are updated in one transaction without query timeout, instances started at the same time wait for it
+func (p *PostgreSQLStore) rekeyDedup() error {
+	ctx := context.Background()
+	tx, err := p.Pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, insertSettingReq, dedupScopeSetting); err != nil {
+		return fmt.Errorf("failed insert setting: %w", err)
+	}
+	var scope string
+	if err := tx.QueryRow(ctx, selectSettingForUpdateReq, dedupScopeSetting).Scan(&scope); err != nil {
+		return fmt.Errorf("failed select setting: %w", err)
+	}
+	if DedupScope(scope) == p.dedup {
+		return nil
+	}
+
+	// keys are cleared before set, unique constraint is checked for every updated row
+	for _, query := range []string{clearDedupKeysReq, setDedupKeysReq} {
+		if _, err := tx.Exec(ctx, query, string(p.dedup)); err != nil {
+			return fmt.Errorf("failed update dedup keys: %w", err)
+		}
+	}
+	if _, err := tx.Exec(ctx, updateSettingReq, dedupScopeSetting, string(p.dedup)); err != nil {
+		return fmt.Errorf("failed update setting: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed commit transaction: %w", err)
+	}
+	p.logger.Info("dedup keys rebuilt", zap.String("from", scope), zap.String("to", string(p.dedup)))
+	return nil
+}
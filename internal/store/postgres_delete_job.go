@@ -0,0 +1,74 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hollgett/shortener.git/internal/models"
+	"github.com/jackc/pgx/v5"
+)
+
+func (p *PostgreSQLStore) SaveDeleteJobs(jobs []models.DeleteJob) error {
+	return p.execDeleteJobs(jobs, func(ctx context.Context, tx pgx.Tx, job models.DeleteJob) error {
+		_, err := tx.Exec(ctx, insertDeleteJobReq, job.ID, job.UserID, job.ShortURL, job.OperationID, job.Attempts, job.NextAttemptAt, job.CreatedAt)
+		return err
+	})
+}
+
+// jobs locked by claim of other instance are skipped, not waited
+func (p *PostgreSQLStore) ClaimDueDeleteJobs(now, leaseUntil time.Time, limit int) ([]models.DeleteJob, error) {
+	return p.queryDeleteJobs(claimDueDeleteJobsReq, now, leaseUntil, limit)
+}
+
+func (p *PostgreSQLStore) UpdateDeleteJobs(jobs []models.DeleteJob) error {
+	return p.execDeleteJobs(jobs, func(ctx context.Context, tx pgx.Tx, job models.DeleteJob) error {
+		_, err := tx.Exec(ctx, updateDeleteJobReq, job.ID, job.Attempts, job.NextAttemptAt, job.LastError, job.Dead)
+		return err
+	})
+}
+
+func (p *PostgreSQLStore) RemoveDeleteJobs(IDs []string) error {
+	if _, err := p.exec(deleteDeleteJobsReq, IDs); err != nil {
+		return fmt.Errorf("failed remove delete jobs: %w", err)
+	}
+	return nil
+}
+
+func (p *PostgreSQLStore) GetDeadDeleteJobs(limit int) ([]models.DeleteJob, error) {
+	return p.queryDeleteJobs(selectDeadDeleteJobsReq, limit)
+}
+
+// exec for every job in single transaction
+func (p *PostgreSQLStore) execDeleteJobs(jobs []models.DeleteJob, exec func(ctx context.Context, tx pgx.Tx, job models.DeleteJob) error) error {
+	return p.inTx(func(ctx context.Context, tx pgx.Tx) error {
+		for _, job := range jobs {
+			if err := exec(ctx, tx, job); err != nil {
+				return fmt.Errorf("failed exec delete job %s: %w", job.ID, err)
+			}
+		}
+		return nil
+	})
+}
+
+func (p *PostgreSQLStore) queryDeleteJobs(query string, args ...any) ([]models.DeleteJob, error) {
+	rows, err := p.query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed query: %w", err)
+	}
+	defer rows.Close()
+
+	jobs := make([]models.DeleteJob, 0)
+	for rows.Next() {
+		var job models.DeleteJob
+		err := rows.Scan(&job.ID, &job.UserID, &job.ShortURL, &job.OperationID, &job.Attempts, &job.NextAttemptAt, &job.LastError, &job.Dead, &job.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed scan rows: %w", err)
+		}
+		jobs = append(jobs, job)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+	return jobs, nil
+}
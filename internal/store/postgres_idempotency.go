@@ -0,0 +1,60 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hollgett/shortener.git/internal/models"
+	"github.com/jackc/pgx/v5"
+)
+
+func (p *PostgreSQLStore) SaveIdempotencyKey(record models.IdempotencyRecord) error {
+	res, err := p.exec(insertIdempotencyKeyReq, record.UserID, record.Key, record.RequestHash, record.CreatedAt, record.ExpiresAt)
+	if err != nil {
+		return fmt.Errorf("failed insert idempotency key: %w", err)
+	}
+	if res.RowsAffected() == 0 {
+		return ErrIdempotencyKeyExists
+	}
+	return nil
+}
+
+func (p *PostgreSQLStore) GetIdempotencyKey(userID, key string) (models.IdempotencyRecord, error) {
+	var record models.IdempotencyRecord
+	err := p.queryRow(selectIdempotencyKeyReq, userID, key).Scan(&record.UserID, &record.Key, &record.RequestHash,
+		&record.Status, &record.ContentType, &record.Body, &record.CreatedAt, &record.ExpiresAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.IdempotencyRecord{}, ErrIdempotencyKeyNotExists
+	} else if err != nil {
+		return models.IdempotencyRecord{}, fmt.Errorf("failed scan idempotency key: %w", err)
+	}
+	return record, nil
+}
+
+func (p *PostgreSQLStore) CompleteIdempotencyKey(record models.IdempotencyRecord) error {
+	res, err := p.exec(completeIdempotencyKeyReq, record.UserID, record.Key, record.Status, record.ContentType, record.Body,
+		record.ExpiresAt, record.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed update idempotency key: %w", err)
+	}
+	if res.RowsAffected() == 0 {
+		return ErrIdempotencyKeyNotExists
+	}
+	return nil
+}
+
+func (p *PostgreSQLStore) DeleteIdempotencyKey(userID, key string) error {
+	if _, err := p.exec(deleteIdempotencyKeyReq, userID, key); err != nil {
+		return fmt.Errorf("failed delete idempotency key: %w", err)
+	}
+	return nil
+}
+
+func (p *PostgreSQLStore) PurgeIdempotencyKeys(expiredBefore time.Time) (int, error) {
+	res, err := p.exec(purgeIdempotencyKeysReq, expiredBefore)
+	if err != nil {
+		return 0, fmt.Errorf("failed purge idempotency keys: %w", err)
+	}
+	return int(res.RowsAffected()), nil
+}
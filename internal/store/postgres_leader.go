@@ -0,0 +1,107 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+
+	"github.com/hollgett/shortener.git/internal/models"
+	"github.com/jackc/pgx/v5"
+)
+
+// session advisory lock, it is held by dedicated connection and released when connection is closed
+type pgLeaderLock struct {
+	store      *PostgreSQLStore
+	key        int64
+	name       string
+	instanceID string
+	conn       *pgx.Conn
+}
+
+func (p *PostgreSQLStore) NewLeaderLock(name, instanceID string) LeaderLock {
+	h := fnv.New64a()
+	h.Write([]byte(name))
+	return &pgLeaderLock{
+		store:      p,
+		key:        int64(h.Sum64()),
+		name:       name,
+		instanceID: instanceID,
+	}
+}
+
+func (p *PostgreSQLStore) GetLeader(name string) (models.Leader, error) {
+	var leader models.Leader
+	err := p.queryRow(selectLeaderReq, name).Scan(&leader.Name, &leader.InstanceID, &leader.AcquiredAt, &leader.RenewedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.Leader{}, ErrLeaderNotExists
+	} else if err != nil {
+		return models.Leader{}, fmt.Errorf("failed scan leader: %w", err)
+	}
+	return leader, nil
+}
+
+func (l *pgLeaderLock) TryLock(ctx context.Context) (bool, error) {
+	if l.conn == nil {
+		conn, err := l.store.dedicatedConn(ctx)
+		if err != nil {
+			return false, fmt.Errorf("failed get connection: %w", err)
+		}
+		l.conn = conn
+	}
+
+	var locked bool
+	if err := l.conn.QueryRow(ctx, tryAdvisoryLockReq, l.key).Scan(&locked); err != nil {
+		l.discard()
+		return false, fmt.Errorf("failed try advisory lock: %w", err)
+	}
+	if !locked {
+		return false, nil
+	}
+	if _, err := l.conn.Exec(ctx, upsertLeaderReq, l.name, l.instanceID); err != nil {
+		l.discard()
+		return false, fmt.Errorf("failed save leader: %w", err)
+	}
+	return true, nil
+}
+
+func (l *pgLeaderLock) Renew(ctx context.Context) error {
+	if l.conn == nil {
+		return ErrLeaderNotExists
+	}
+	res, err := l.conn.Exec(ctx, renewLeaderReq, l.name, l.instanceID)
+	if err != nil {
+		l.discard()
+		return fmt.Errorf("failed renew leader: %w", err)
+	}
+	// record was removed, lock is still held by connection
+	if res.RowsAffected() == 0 {
+		if _, err := l.conn.Exec(ctx, upsertLeaderReq, l.name, l.instanceID); err != nil {
+			l.discard()
+			return fmt.Errorf("failed save leader: %w", err)
+		}
+	}
+	return nil
+}
+
+func (l *pgLeaderLock) Unlock(ctx context.Context) error {
+	if l.conn == nil {
+		return nil
+	}
+	defer l.discard()
+
+	var errs []error
+	if _, err := l.conn.Exec(ctx, deleteLeaderReq, l.name, l.instanceID); err != nil {
+		errs = append(errs, fmt.Errorf("failed delete leader: %w", err))
+	}
+	if _, err := l.conn.Exec(ctx, advisoryUnlockReq, l.key); err != nil {
+		errs = append(errs, fmt.Errorf("failed advisory unlock: %w", err))
+	}
+	return errors.Join(errs...)
+}
+
+// close connection without return to pool, so lock can't stay on pooled connection
+func (l *pgLeaderLock) discard() {
+	discardConn(l.conn)
+	l.conn = nil
+}
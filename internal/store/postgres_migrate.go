@@ -0,0 +1,84 @@
+package store
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hollgett/shortener.git/internal/models"
+)
+
+// HasURLsTable check that table of links is created by migrations
+func (p *PostgreSQLStore) HasURLsTable() (bool, error) {
+	var exists bool
+	if err := p.queryRow(hasURLsTableReq).Scan(&exists); err != nil {
+		return false, fmt.Errorf("failed check table: %w", err)
+	}
+	return exists, nil
+}
+
+func (p *PostgreSQLStore) ScanURLs(userID, afterShort string, limit int) ([]models.ShortenerURL, error) {
+	rows, err := p.query(scanURLsReq, userID, afterShort, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed query: %w", err)
+	}
+	defer rows.Close()
+
+	URLs := make([]models.ShortenerURL, 0)
+	for rows.Next() {
+		var URL models.ShortenerURL
+		err := rows.Scan(&URL.ShortURL, &URL.OriginalURL, &URL.UserID, &URL.WorkspaceID, &URL.DeletedFlag, &URL.DeletedAt, &URL.DisabledFlag,
+			&URL.RedirectStatus, &URL.CacheControl, &URL.Tracked, &URL.Title, &URL.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed scan rows: %w", err)
+		}
+		URLs = append(URLs, URL)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+	return URLs, nil
+}
+
+func (p *PostgreSQLStore) ImportURLs(URLs []models.ShortenerURL) (int, error) {
+	var (
+		shorts, originals, userIDs, workspaceIDs, keys, cacheControls, titles []string
+		deleted, disabled, tracked                                            []bool
+		deletedAt, createdAt                                                  []*time.Time
+		redirectStatuses                                                      []int32
+	)
+	// duplicates in batch are resolved here, statement sees only rows existing before it
+	seenShorts := make(map[string]bool, len(URLs))
+	seenKeys := make(map[string]bool, len(URLs))
+	for _, URL := range URLs {
+		if seenShorts[URL.ShortURL] {
+			continue
+		}
+		seenShorts[URL.ShortURL] = true
+		key := p.dedup.key(URL)
+		if seenKeys[key] {
+			key = ""
+		}
+		seenKeys[key] = true
+
+		shorts = append(shorts, URL.ShortURL)
+		originals = append(originals, URL.OriginalURL)
+		userIDs = append(userIDs, URL.UserID)
+		workspaceIDs = append(workspaceIDs, URL.WorkspaceID)
+		keys = append(keys, key)
+		deleted = append(deleted, URL.DeletedFlag)
+		deletedAt = append(deletedAt, URL.DeletedAt)
+		disabled = append(disabled, URL.DisabledFlag)
+		redirectStatuses = append(redirectStatuses, int32(URL.RedirectStatus))
+		cacheControls = append(cacheControls, URL.CacheControl)
+		tracked = append(tracked, URL.Tracked)
+		titles = append(titles, URL.Title)
+		createdAt = append(createdAt, URL.CreatedAt)
+	}
+
+	res, err := p.exec(importURLsReq, shorts, originals, userIDs, workspaceIDs, keys, deleted, deletedAt, disabled,
+		redirectStatuses, cacheControls, tracked, titles, createdAt)
+	if err != nil {
+		return 0, fmt.Errorf("failed import urls: %w", err)
+	}
+	return int(res.RowsAffected()), nil
+}
@@ -0,0 +1,57 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+const (
+	cacheChannel = "shortener_cache_invalidate"
+	// payload of notification must be shorter than 8000 bytes
+	maxNotifyPayload = 7000
+)
+
+func (p *PostgreSQLStore) PublishInvalidate(shortURLs []string) error {
+	var payload strings.Builder
+	for _, short := range shortURLs {
+		if payload.Len() != 0 && payload.Len()+len(short)+1 > maxNotifyPayload {
+			if _, err := p.exec(notifyReq, cacheChannel, payload.String()); err != nil {
+				return fmt.Errorf("failed notify: %w", err)
+			}
+			payload.Reset()
+		}
+		if payload.Len() != 0 {
+			payload.WriteByte(',')
+		}
+		payload.WriteString(short)
+	}
+	if payload.Len() == 0 {
+		return nil
+	}
+	if _, err := p.exec(notifyReq, cacheChannel, payload.String()); err != nil {
+		return fmt.Errorf("failed notify: %w", err)
+	}
+	return nil
+}
+
+func (p *PostgreSQLStore) ListenInvalidate(ctx context.Context, ready func(), invalidate func(shortURLs []string)) error {
+	conn, err := p.dedicatedConn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed get connection: %w", err)
+	}
+	// connection is listening, it must not be returned to pool
+	defer discardConn(conn)
+
+	if _, err := conn.Exec(ctx, "LISTEN "+cacheChannel); err != nil {
+		return fmt.Errorf("failed listen: %w", err)
+	}
+	ready()
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return fmt.Errorf("failed wait notification: %w", err)
+		}
+		invalidate(strings.Split(notification.Payload, ","))
+	}
+}
@@ -0,0 +1,71 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/hollgett/shortener.git/internal/models"
+	"github.com/jackc/pgx/v5"
+)
+
+func (p *PostgreSQLStore) CreateDeleteOperation(operation models.DeleteOperation) error {
+	return p.inTx(func(ctx context.Context, tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, insertDeleteOperationReq, operation.ID, operation.UserID, operation.CreatedAt, operation.UpdatedAt); err != nil {
+			return fmt.Errorf("failed insert delete operation: %w", err)
+		}
+		for _, result := range operation.Results {
+			if _, err := tx.Exec(ctx, insertDeleteResultReq, operation.ID, result.ShortURL, result.Status, result.Error); err != nil {
+				return fmt.Errorf("failed insert delete result %s: %w", result.ShortURL, err)
+			}
+		}
+		return nil
+	})
+}
+
+func (p *PostgreSQLStore) GetDeleteOperation(operationID string) (models.DeleteOperation, error) {
+	var operation models.DeleteOperation
+	err := p.queryRow(selectDeleteOperationReq, operationID).Scan(&operation.ID, &operation.UserID, &operation.CreatedAt, &operation.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.DeleteOperation{}, ErrOperationNotExists
+	} else if err != nil {
+		return models.DeleteOperation{}, fmt.Errorf("failed scan delete operation: %w", err)
+	}
+
+	rows, err := p.query(selectDeleteResultsReq, operationID)
+	if err != nil {
+		return models.DeleteOperation{}, fmt.Errorf("failed query: %w", err)
+	}
+	defer rows.Close()
+
+	operation.Results = make([]models.DeleteResult, 0)
+	for rows.Next() {
+		var result models.DeleteResult
+		if err := rows.Scan(&result.ShortURL, &result.Status, &result.Error); err != nil {
+			return models.DeleteOperation{}, fmt.Errorf("failed scan rows: %w", err)
+		}
+		operation.Results = append(operation.Results, result)
+	}
+	if err := rows.Err(); err != nil {
+		return models.DeleteOperation{}, fmt.Errorf("rows error: %w", err)
+	}
+	return operation, nil
+}
+
+func (p *PostgreSQLStore) SetDeleteResults(operationID string, results []models.DeleteResult) error {
+	return p.inTx(func(ctx context.Context, tx pgx.Tx) error {
+		res, err := tx.Exec(ctx, touchDeleteOperationReq, operationID)
+		if err != nil {
+			return fmt.Errorf("failed update delete operation: %w", err)
+		}
+		if res.RowsAffected() == 0 {
+			return ErrOperationNotExists
+		}
+		for _, result := range results {
+			if _, err := tx.Exec(ctx, updateDeleteResultReq, operationID, result.ShortURL, result.Status, result.Error); err != nil {
+				return fmt.Errorf("failed update delete result %s: %w", result.ShortURL, err)
+			}
+		}
+		return nil
+	})
+}
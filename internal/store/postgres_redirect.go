@@ -0,0 +1,40 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/hollgett/shortener.git/internal/models"
+	"github.com/jackc/pgx/v5"
+)
+
+func (p *PostgreSQLStore) GetRedirect(ctx context.Context, shortURL string) (models.Redirect, error) {
+	row := p.queryRowContext(ctx, SelectOriginalReq, shortURL)
+
+	var redirect models.Redirect
+	var isDeleted, isDisabled bool
+	err := row.Scan(&redirect.OriginalURL, &isDeleted, &isDisabled,
+		&redirect.RedirectStatus, &redirect.CacheControl, &redirect.Tracked, &redirect.Title, &redirect.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.Redirect{}, ErrIsNotExists
+	} else if err != nil {
+		return models.Redirect{}, fmt.Errorf("failed scan row: %w", err)
+	}
+
+	if isDeleted {
+		return models.Redirect{}, ErrURLDeleted
+	}
+	if isDisabled {
+		return models.Redirect{}, ErrURLDisabled
+	}
+	return redirect, nil
+}
+
+func (p *PostgreSQLStore) SetURLRedirect(shortURL string, policy models.RedirectPolicy) error {
+	return p.execAffectedURL(setURLRedirectReq, shortURL, policy.RedirectStatus, policy.CacheControl, policy.Tracked)
+}
+
+func (p *PostgreSQLStore) SetURLTitle(shortURL, title string) error {
+	return p.execAffectedURL(setURLTitleReq, shortURL, title)
+}
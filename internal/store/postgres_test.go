@@ -0,0 +1,241 @@
+package store
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"os"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/hollgett/shortener.git/internal/logger"
+	"github.com/hollgett/shortener.git/internal/models"
+)
+
+// queries are checked against real database set by TEST_DATABASE_DSN, tests are skipped without it
+func newTestPostgreSQLStore(t *testing.T) *PostgreSQLStore {
+	return newTestPostgreSQLStoreScope(t, DedupGlobal)
+}
+
+func newTestPostgreSQLStoreScope(t *testing.T, dedup DedupScope) *PostgreSQLStore {
+	t.Helper()
+	DSN := os.Getenv("TEST_DATABASE_DSN")
+	if DSN == "" {
+		t.Skip("TEST_DATABASE_DSN is not set")
+	}
+	// migrations are read relative to module root
+	if _, err := os.Stat("db/migrations"); err != nil {
+		t.Chdir("../..")
+	}
+
+	log, err := logger.NewLogger()
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := NewPostgreSQLStore(log, DSN, PoolConfig{MaxConns: 4, QueryTimeout: 5 * time.Second}, dedup)
+	if err != nil {
+		t.Fatalf("NewPostgreSQLStore() error = %v", err)
+	}
+	t.Cleanup(func() { p.Close() })
+	return p
+}
+
+// random id of n bytes in hex, keeps rows of runs apart in shared database
+func testID(t *testing.T, n int) string {
+	t.Helper()
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		t.Fatal(err)
+	}
+	return hex.EncodeToString(b)
+}
+
+func TestPostgreSQLStoreAudit(t *testing.T) {
+	p := newTestPostgreSQLStore(t)
+
+	actor := testID(t, 4)
+	start := time.Now().UTC().Truncate(time.Microsecond)
+	records := []models.AuditRecord{
+		{ID: testID(t, 16), ActorID: actor, RequestID: "req-1", IP: "192.0.2.1", Action: models.AuditLinkCreate,
+			ShortURL: "aaa", After: "https://a.example", CreatedAt: start},
+		{ID: testID(t, 16), ActorID: actor, RequestID: "req-2", IP: "192.0.2.2", Action: models.AuditLinkUpdate,
+			ShortURL: "aaa", Before: "https://a.example", After: "https://b.example", Details: "d",
+			CreatedAt: start.Add(time.Second)},
+		{ID: testID(t, 16), ActorID: actor, Action: models.AuditLinkCreate, ShortURL: "bbb",
+			CreatedAt: start.Add(2 * time.Second)},
+	}
+	for _, record := range records {
+		if err := p.SaveAuditRecord(record); err != nil {
+			t.Fatalf("SaveAuditRecord() error = %v", err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		filter models.AuditFilter
+		want   []models.AuditRecord
+	}{
+		{name: "actor", filter: models.AuditFilter{ActorID: actor},
+			want: []models.AuditRecord{records[2], records[1], records[0]}},
+		{name: "limit", filter: models.AuditFilter{ActorID: actor, Limit: 1},
+			want: []models.AuditRecord{records[2]}},
+		{name: "short", filter: models.AuditFilter{ActorID: actor, ShortURL: "aaa"},
+			want: []models.AuditRecord{records[1], records[0]}},
+		{name: "action", filter: models.AuditFilter{ActorID: actor, Action: models.AuditLinkCreate},
+			want: []models.AuditRecord{records[2], records[0]}},
+		{name: "range", filter: models.AuditFilter{ActorID: actor, From: start.Add(time.Second), To: start.Add(2 * time.Second)},
+			want: []models.AuditRecord{records[1]}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := p.GetAuditRecords(tt.filter)
+			if err != nil {
+				t.Fatalf("GetAuditRecords() error = %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("GetAuditRecords() = %d records, want %d", len(got), len(tt.want))
+			}
+			for i := range got {
+				if !got[i].CreatedAt.Equal(tt.want[i].CreatedAt) {
+					t.Errorf("record %d created_at = %v, want %v", i, got[i].CreatedAt, tt.want[i].CreatedAt)
+				}
+				got[i].CreatedAt = tt.want[i].CreatedAt
+				if got[i] != tt.want[i] {
+					t.Errorf("record %d = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestPostgreSQLStoreClaimDueDeleteJobs(t *testing.T) {
+	p := newTestPostgreSQLStore(t)
+
+	// jobs are due long ago, so claims do not take jobs of running instances
+	base := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	jobs := make([]models.DeleteJob, 4)
+	IDs := make([]string, len(jobs))
+	for i := range jobs {
+		IDs[i] = testID(t, 16)
+		jobs[i] = models.DeleteJob{ID: IDs[i], UserID: testID(t, 4), ShortURL: testID(t, 4),
+			NextAttemptAt: base.Add(-time.Hour), CreatedAt: base.Add(time.Duration(i) * time.Second)}
+	}
+	if err := p.SaveDeleteJobs(jobs); err != nil {
+		t.Fatalf("SaveDeleteJobs() error = %v", err)
+	}
+	t.Cleanup(func() { p.RemoveDeleteJobs(IDs) })
+
+	// concurrent claims take different jobs
+	leaseUntil := base.Add(time.Hour)
+	claimed := make(chan []models.DeleteJob, 2)
+	for range 2 {
+		go func() {
+			jobs, err := p.ClaimDueDeleteJobs(base, leaseUntil, 2)
+			if err != nil {
+				t.Errorf("ClaimDueDeleteJobs() error = %v", err)
+			}
+			claimed <- jobs
+		}()
+	}
+	seen := make(map[string]bool)
+	for range 2 {
+		for _, job := range <-claimed {
+			if seen[job.ID] {
+				t.Errorf("job %s claimed twice", job.ID)
+			}
+			seen[job.ID] = true
+			if !job.NextAttemptAt.Equal(leaseUntil) {
+				t.Errorf("job %s next attempt = %v, want %v", job.ID, job.NextAttemptAt, leaseUntil)
+			}
+		}
+	}
+	if len(seen) != len(jobs) {
+		t.Fatalf("claimed %d jobs, want %d", len(seen), len(jobs))
+	}
+
+	// leased jobs are skipped until lease is expired
+	if got, err := p.ClaimDueDeleteJobs(base, leaseUntil, 10); err != nil || len(got) != 0 {
+		t.Errorf("ClaimDueDeleteJobs() during lease = %d jobs, %v, want none", len(got), err)
+	}
+	if got, err := p.ClaimDueDeleteJobs(leaseUntil, leaseUntil.Add(time.Hour), 10); err != nil || len(got) != len(jobs) {
+		t.Errorf("ClaimDueDeleteJobs() after lease = %d jobs, %v, want %d", len(got), err, len(jobs))
+	}
+}
+
+func TestPostgreSQLStoreGetURLs(t *testing.T) {
+	p := newTestPostgreSQLStore(t)
+
+	userID := testID(t, 4)
+	shorts := []string{testID(t, 4), testID(t, 4)}
+	for _, short := range shorts {
+		URL := models.ShortenerURL{UserID: userID, ShortURL: short, OriginalURL: "https://" + short + ".example"}
+		if _, err := p.SaveShortURL(t.Context(), URL); err != nil {
+			t.Fatalf("SaveShortURL() error = %v", err)
+		}
+		t.Cleanup(func() { p.HardDeleteURL(short) })
+	}
+
+	got, err := p.GetURLs([]string{shorts[1], testID(t, 4), shorts[0]})
+	if err != nil {
+		t.Fatalf("GetURLs() error = %v", err)
+	}
+	found := make(map[string]models.AdminURL, len(got))
+	for _, URL := range got {
+		found[URL.ShortURL] = URL
+	}
+	if len(found) != len(shorts) {
+		t.Fatalf("GetURLs() = %d links, want %d", len(found), len(shorts))
+	}
+	for _, short := range shorts {
+		if URL := found[short]; URL.UserID != userID || URL.OriginalURL != "https://"+short+".example" {
+			t.Errorf("GetURLs() link %s = %+v", short, URL)
+		}
+	}
+}
+
+func TestPostgreSQLStoreRekeyDedup(t *testing.T) {
+	p := newTestPostgreSQLStore(t)
+
+	base := time.Now().UTC().Truncate(time.Microsecond)
+	original := "https://" + testID(t, 8) + ".example"
+	users := []string{testID(t, 4), testID(t, 4)}
+	URLs := make([]models.ShortenerURL, 3)
+	for i := range URLs {
+		createdAt := base.Add(time.Duration(i) * time.Second)
+		URLs[i] = models.ShortenerURL{ShortURL: testID(t, 4), OriginalURL: original, UserID: users[i%2], CreatedAt: &createdAt}
+		t.Cleanup(func() { p.HardDeleteURL(URLs[i].ShortURL) })
+	}
+	if _, err := p.ImportURLs(URLs); err != nil {
+		t.Fatalf("ImportURLs() error = %v", err)
+	}
+
+	keys := func(p *PostgreSQLStore) []string {
+		got := make([]string, len(URLs))
+		for i, URL := range URLs {
+			var key *string
+			if err := p.queryRow(`SELECT dedup_key FROM shortener_urls WHERE short = $1`, URL.ShortURL).Scan(&key); err != nil {
+				t.Fatalf("select dedup key error = %v", err)
+			}
+			if key != nil {
+				got[i] = *key
+			}
+		}
+		return got
+	}
+	tests := []struct {
+		dedup DedupScope
+		want  []string
+	}{
+		{dedup: DedupUser, want: []string{users[0] + "|" + original, users[1] + "|" + original, ""}},
+		{dedup: DedupNone, want: []string{"", "", ""}},
+		{dedup: DedupGlobal, want: []string{original, "", ""}},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.dedup), func(t *testing.T) {
+			got := keys(newTestPostgreSQLStoreScope(t, tt.dedup))
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("dedup keys = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
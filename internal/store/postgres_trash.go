@@ -0,0 +1,58 @@
+package store
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hollgett/shortener.git/internal/models"
+)
+
+func (p *PostgreSQLStore) GetDeletedUserURLs(userID string) ([]models.DeletedURLResponse, error) {
+	rows, err := p.query(selectDeletedUserURLsReq, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed query: %w", err)
+	}
+	defer rows.Close()
+
+	URLs := make([]models.DeletedURLResponse, 0)
+	for rows.Next() {
+		var URL models.DeletedURLResponse
+		if err := rows.Scan(&URL.ShortURL, &URL.OriginalURL, &URL.DeletedAt); err != nil {
+			return nil, fmt.Errorf("failed scan rows: %w", err)
+		}
+		URLs = append(URLs, URL)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+	return URLs, nil
+}
+
+func (p *PostgreSQLStore) RestoreURLs(userID string, shortURLs []string) ([]string, error) {
+	rows, err := p.query(restoreURLsReq, userID, shortURLs)
+	if err != nil {
+		return nil, fmt.Errorf("failed restore urls: %w", err)
+	}
+	defer rows.Close()
+
+	restored := make([]string, 0, len(shortURLs))
+	for rows.Next() {
+		var short string
+		if err := rows.Scan(&short); err != nil {
+			return nil, fmt.Errorf("failed scan rows: %w", err)
+		}
+		restored = append(restored, short)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+	return restored, nil
+}
+
+func (p *PostgreSQLStore) PurgeDeletedURLs(deletedBefore time.Time) (int, error) {
+	res, err := p.exec(purgeDeletedURLsReq, deletedBefore)
+	if err != nil {
+		return 0, fmt.Errorf("failed purge deleted urls: %w", err)
+	}
+	return int(res.RowsAffected()), nil
+}
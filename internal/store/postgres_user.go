@@ -0,0 +1,103 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/hollgett/shortener.git/internal/models"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5"
+)
+
+func (p *PostgreSQLStore) CreateUser(user models.User) error {
+	_, err := p.exec(insertUserReq, user.ID, user.Login, user.PasswordHash, user.CreatedAt)
+	if pgErr := getPGError(err); pgErr != nil && pgErr.Code == pgerrcode.UniqueViolation {
+		return ErrUserExists
+	} else if err != nil {
+		return fmt.Errorf("failed insert user: %w", err)
+	}
+	return nil
+}
+
+func (p *PostgreSQLStore) GetUserByLogin(login string) (models.User, error) {
+	return p.selectUser(selectUserByLoginReq, login)
+}
+
+func (p *PostgreSQLStore) GetUserByID(userID string) (models.User, error) {
+	return p.selectUser(selectUserByIDReq, userID)
+}
+
+func (p *PostgreSQLStore) selectUser(query string, arg string) (models.User, error) {
+	var user models.User
+	err := p.queryRow(query, arg).Scan(&user.ID, &user.Login, &user.PasswordHash, &user.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.User{}, ErrUserNotExists
+	} else if err != nil {
+		return models.User{}, fmt.Errorf("failed scan user: %w", err)
+	}
+	return user, nil
+}
+
+func (p *PostgreSQLStore) ReassignUserURLs(fromUserID, toUserID string) error {
+	if _, err := p.exec(reassignUserURLsReq, fromUserID, toUserID); err != nil {
+		return fmt.Errorf("failed reassign user urls: %w", err)
+	}
+	return nil
+}
+
+func (p *PostgreSQLStore) CreateSession(session models.Session) error {
+	if _, err := p.exec(insertSessionReq, session.ID, session.UserID, session.CreatedAt, session.Revoked); err != nil {
+		return fmt.Errorf("failed insert session: %w", err)
+	}
+	return nil
+}
+
+func (p *PostgreSQLStore) GetSession(sessionID string) (models.Session, error) {
+	var session models.Session
+	err := p.queryRow(selectSessionReq, sessionID).Scan(&session.ID, &session.UserID, &session.CreatedAt, &session.Revoked)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.Session{}, ErrSessionNotExists
+	} else if err != nil {
+		return models.Session{}, fmt.Errorf("failed scan session: %w", err)
+	}
+	return session, nil
+}
+
+func (p *PostgreSQLStore) RevokeSession(sessionID string) error {
+	res, err := p.exec(revokeSessionReq, sessionID)
+	if err != nil {
+		return fmt.Errorf("failed revoke session: %w", err)
+	}
+	if res.RowsAffected() == 0 {
+		return ErrSessionNotExists
+	}
+	return nil
+}
+
+func (p *PostgreSQLStore) RevokeUserSessions(userID string) error {
+	if _, err := p.exec(revokeUserSessionsReq, userID); err != nil {
+		return fmt.Errorf("failed revoke user sessions: %w", err)
+	}
+	return nil
+}
+
+func (p *PostgreSQLStore) CreateUserIdentity(identity models.UserIdentity) error {
+	_, err := p.exec(insertIdentityReq, identity.Issuer, identity.Subject, identity.UserID, identity.Email, identity.CreatedAt)
+	if pgErr := getPGError(err); pgErr != nil && pgErr.Code == pgerrcode.UniqueViolation {
+		return ErrIdentityExists
+	} else if err != nil {
+		return fmt.Errorf("failed insert identity: %w", err)
+	}
+	return nil
+}
+
+func (p *PostgreSQLStore) GetUserIdentity(issuer, subject string) (models.UserIdentity, error) {
+	var identity models.UserIdentity
+	err := p.queryRow(selectIdentityReq, issuer, subject).Scan(&identity.Issuer, &identity.Subject, &identity.UserID, &identity.Email, &identity.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.UserIdentity{}, ErrIdentityNotExists
+	} else if err != nil {
+		return models.UserIdentity{}, fmt.Errorf("failed scan identity: %w", err)
+	}
+	return identity, nil
+}
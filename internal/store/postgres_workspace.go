@@ -0,0 +1,160 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/hollgett/shortener.git/internal/models"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5"
+)
+
+func (p *PostgreSQLStore) CreateWorkspace(workspace models.Workspace, owner models.WorkspaceMember) error {
+	return p.inTx(func(ctx context.Context, tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, insertWorkspaceReq, workspace.ID, workspace.Name, workspace.CreatedAt); err != nil {
+			return fmt.Errorf("failed insert workspace: %w", err)
+		}
+		if _, err := tx.Exec(ctx, upsertMemberReq, owner.WorkspaceID, owner.UserID, owner.Role, owner.CreatedAt); err != nil {
+			return fmt.Errorf("failed insert workspace owner: %w", err)
+		}
+		return nil
+	})
+}
+
+func (p *PostgreSQLStore) GetWorkspace(workspaceID string) (models.Workspace, error) {
+	var workspace models.Workspace
+	err := p.queryRow(selectWorkspaceReq, workspaceID).Scan(&workspace.ID, &workspace.Name, &workspace.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.Workspace{}, ErrWorkspaceNotExists
+	} else if err != nil {
+		return models.Workspace{}, fmt.Errorf("failed scan workspace: %w", err)
+	}
+	return workspace, nil
+}
+
+func (p *PostgreSQLStore) GetUserWorkspaces(userID string) ([]models.WorkspaceResponse, error) {
+	rows, err := p.query(selectUserWorkspaceReq, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed query: %w", err)
+	}
+	defer rows.Close()
+
+	workspaces := make([]models.WorkspaceResponse, 0)
+	for rows.Next() {
+		var workspace models.WorkspaceResponse
+		if err := rows.Scan(&workspace.ID, &workspace.Name, &workspace.Role, &workspace.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed scan rows: %w", err)
+		}
+		workspaces = append(workspaces, workspace)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+	return workspaces, nil
+}
+
+func (p *PostgreSQLStore) GetWorkspaceMember(workspaceID, userID string) (models.WorkspaceMember, error) {
+	var member models.WorkspaceMember
+	err := p.queryRow(selectMemberReq, workspaceID, userID).Scan(&member.WorkspaceID, &member.UserID, &member.Role, &member.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.WorkspaceMember{}, ErrMemberNotExists
+	} else if err != nil {
+		return models.WorkspaceMember{}, fmt.Errorf("failed scan workspace member: %w", err)
+	}
+	return member, nil
+}
+
+func (p *PostgreSQLStore) GetWorkspaceMembers(workspaceID string) ([]models.WorkspaceMember, error) {
+	rows, err := p.query(selectMembersReq, workspaceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed query: %w", err)
+	}
+	defer rows.Close()
+
+	members := make([]models.WorkspaceMember, 0)
+	for rows.Next() {
+		var member models.WorkspaceMember
+		if err := rows.Scan(&member.WorkspaceID, &member.UserID, &member.Role, &member.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed scan rows: %w", err)
+		}
+		members = append(members, member)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+	return members, nil
+}
+
+func (p *PostgreSQLStore) SaveWorkspaceMember(member models.WorkspaceMember) error {
+	_, err := p.exec(upsertMemberReq, member.WorkspaceID, member.UserID, member.Role, member.CreatedAt)
+	if pgErr := getPGError(err); pgErr != nil && pgErr.Code == pgerrcode.ForeignKeyViolation {
+		return ErrWorkspaceNotExists
+	} else if err != nil {
+		return fmt.Errorf("failed save workspace member: %w", err)
+	}
+	return nil
+}
+
+func (p *PostgreSQLStore) DeleteWorkspaceMember(workspaceID, userID string) error {
+	res, err := p.exec(deleteMemberReq, workspaceID, userID)
+	if err != nil {
+		return fmt.Errorf("failed delete workspace member: %w", err)
+	}
+	if res.RowsAffected() == 0 {
+		return ErrMemberNotExists
+	}
+	return nil
+}
+
+func (p *PostgreSQLStore) GetWorkspaceURLs(workspaceID string) ([]models.URLResponse, error) {
+	rows, err := p.query(selectWorkspaceURLsReq, workspaceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed query: %w", err)
+	}
+	defer rows.Close()
+
+	URLs := make([]models.URLResponse, 0)
+	for rows.Next() {
+		var URL models.URLResponse
+		if err := rows.Scan(&URL.ShortURL, &URL.OriginalURL); err != nil {
+			return nil, fmt.Errorf("failed scan rows: %w", err)
+		}
+		URLs = append(URLs, URL)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+	return URLs, nil
+}
+
+func (p *PostgreSQLStore) MoveURLToWorkspace(userID, shortURL, workspaceID string) error {
+	return p.execAffectedURL(moveURLToWorkspaceReq, userID, shortURL, workspaceID)
+}
+
+func (p *PostgreSQLStore) UpdateWorkspaceURL(workspaceID, shortURL, originalURL string) error {
+	err := p.execAffectedURL(updateWorkspaceURLReq, workspaceID, shortURL, originalURL)
+	if pgErr := getPGError(err); pgErr != nil && pgErr.Code == pgerrcode.UniqueViolation {
+		return ErrShortExists
+	}
+	return err
+}
+
+func (p *PostgreSQLStore) DeleteWorkspaceURLs(workspaceID string, shortURLs []string) error {
+	if _, err := p.exec(deleteWorkspaceURLsReq, workspaceID, shortURLs); err != nil {
+		return fmt.Errorf("failed delete workspace urls: %w", err)
+	}
+	return nil
+}
+
+// exec update of single url, return ErrIsNotExists if no rows affected
+func (p *PostgreSQLStore) execAffectedURL(query string, args ...any) error {
+	res, err := p.exec(query, args...)
+	if err != nil {
+		return fmt.Errorf("failed update url: %w", err)
+	}
+	if res.RowsAffected() == 0 {
+		return ErrIsNotExists
+	}
+	return nil
+}
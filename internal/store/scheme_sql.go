@@ -1,8 +1,189 @@
 package store
 
 const (
-	InsertReq         = `INSERT INTO shortener_urls(original, short, user_id) VALUES ($1, $2, $3)`
-	selectShortReq    = `SELECT short FROM shortener_urls WHERE original = $1`
-	SelectOriginalReq = `SELECT original, is_deleted FROM shortener_urls WHERE short = $1`
-	SelectUserURLsReq = `SELECT short,original FROM shortener_urls WHERE user_id = $1`
+	selectShortReq    = `SELECT short FROM shortener_urls WHERE dedup_key = $1`
+	SelectOriginalReq = `SELECT original, is_deleted, is_disabled, redirect_status, cache_control, tracked, title, created_at FROM shortener_urls WHERE short = $1`
+	SelectUserURLsReq = `SELECT short,original FROM shortener_urls WHERE user_id = $1 AND workspace_id IS NULL`
+	selectStatsReq    = `SELECT COUNT(*), COUNT(DISTINCT user_id), COUNT(*) FILTER (WHERE is_deleted) FROM shortener_urls`
+
+	// first occurrence of dedup key in batch wins, others are reported as conflict, links without key are returned by short
+	insertURLsReq = `INSERT INTO shortener_urls(original, short, user_id, workspace_id, dedup_key, redirect_status, cache_control, tracked,
+		title, created_at)
+	SELECT original, short, user_id, NULLIF(workspace_id, ''), NULLIF(dedup_key, ''), redirect_status, cache_control, tracked,
+		title, COALESCE(created_at, now())
+	FROM unnest($1::text[], $2::text[], $3::text[], $4::text[], $5::text[], $6::smallint[], $7::text[], $8::bool[], $9::text[], $10::timestamptz[])
+		WITH ORDINALITY AS t(original, short, user_id, workspace_id, dedup_key, redirect_status, cache_control, tracked, title, created_at, n)
+	ORDER BY n
+	ON CONFLICT (dedup_key) DO NOTHING
+	RETURNING COALESCE(dedup_key, short), short`
+	selectShortsReq = `SELECT dedup_key, short FROM shortener_urls WHERE dedup_key = ANY($1)`
+	InsertReq       = `INSERT INTO shortener_urls(original, short, user_id, workspace_id, dedup_key, redirect_status, cache_control, tracked,
+		title, created_at)
+	VALUES ($1, $2, $3, NULLIF($4, ''), NULLIF($5, ''), $6, $7, $8, $9, COALESCE($10, now()))`
+)
+
+const (
+	insertUserReq         = `INSERT INTO shortener_users(id, login, password_hash, created_at) VALUES ($1, $2, $3, $4)`
+	selectUserByLoginReq  = `SELECT id, login, password_hash, created_at FROM shortener_users WHERE login = $1`
+	selectUserByIDReq     = `SELECT id, login, password_hash, created_at FROM shortener_users WHERE id = $1`
+	insertSessionReq      = `INSERT INTO shortener_sessions(id, user_id, created_at, revoked) VALUES ($1, $2, $3, $4)`
+	selectSessionReq      = `SELECT id, user_id, created_at, revoked FROM shortener_sessions WHERE id = $1`
+	revokeSessionReq      = `UPDATE shortener_sessions SET revoked = TRUE WHERE id = $1`
+	revokeUserSessionsReq = `UPDATE shortener_sessions SET revoked = TRUE WHERE user_id = $1`
+
+	// per user dedup key is moved to new owner, it is dropped if new owner already has link of original
+	reassignUserURLsReq = `UPDATE shortener_urls AS s SET user_id = $2,
+	dedup_key = CASE WHEN s.dedup_key IS DISTINCT FROM $1 || '|' || s.original THEN s.dedup_key
+		WHEN EXISTS (SELECT 1 FROM shortener_urls o WHERE o.dedup_key = $2 || '|' || s.original) THEN NULL
+		ELSE $2 || '|' || s.original END
+	WHERE s.user_id = $1`
+)
+
+const (
+	insertAPIKeyReq = `INSERT INTO shortener_api_keys(id, user_id, name, hash, scopes, created_at, expires_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $7)`
+	selectAPIKeyReq      = `SELECT id, user_id, name, hash, scopes, created_at, last_used_at, expires_at, revoked FROM shortener_api_keys WHERE id = $1`
+	selectUserAPIKeysReq = `SELECT id, user_id, name, hash, scopes, created_at, last_used_at, expires_at, revoked FROM shortener_api_keys WHERE user_id = $1 ORDER BY created_at`
+	revokeAPIKeyReq      = `UPDATE shortener_api_keys SET revoked = TRUE WHERE user_id = $1 AND id = $2`
+	touchAPIKeyReq       = `UPDATE shortener_api_keys SET last_used_at = $2 WHERE id = $1`
+)
+
+const (
+	insertIdentityReq = `INSERT INTO shortener_user_identities(issuer, subject, user_id, email, created_at) VALUES ($1, $2, $3, $4, $5)`
+	selectIdentityReq = `SELECT issuer, subject, user_id, email, created_at FROM shortener_user_identities WHERE issuer = $1 AND subject = $2`
+)
+
+const (
+	insertWorkspaceReq     = `INSERT INTO shortener_workspaces(id, name, created_at) VALUES ($1, $2, $3)`
+	selectWorkspaceReq     = `SELECT id, name, created_at FROM shortener_workspaces WHERE id = $1`
+	selectUserWorkspaceReq = `SELECT w.id, w.name, m.role, w.created_at FROM shortener_workspaces AS w
+	JOIN shortener_workspace_members AS m ON m.workspace_id = w.id WHERE m.user_id = $1 ORDER BY w.created_at`
+	upsertMemberReq = `INSERT INTO shortener_workspace_members(workspace_id, user_id, role, created_at) VALUES ($1, $2, $3, $4)
+	ON CONFLICT (workspace_id, user_id) DO UPDATE SET role = EXCLUDED.role`
+	selectMemberReq        = `SELECT workspace_id, user_id, role, created_at FROM shortener_workspace_members WHERE workspace_id = $1 AND user_id = $2`
+	selectMembersReq       = `SELECT workspace_id, user_id, role, created_at FROM shortener_workspace_members WHERE workspace_id = $1 ORDER BY created_at`
+	deleteMemberReq        = `DELETE FROM shortener_workspace_members WHERE workspace_id = $1 AND user_id = $2`
+	selectWorkspaceURLsReq = `SELECT short, original FROM shortener_urls WHERE workspace_id = $1 AND is_deleted = FALSE`
+	moveURLToWorkspaceReq  = `UPDATE shortener_urls SET workspace_id = $3 WHERE user_id = $1 AND short = $2 AND is_deleted = FALSE`
+	deleteWorkspaceURLsReq = `UPDATE shortener_urls SET is_deleted = TRUE, deleted_at = now() WHERE workspace_id = $1 AND short = ANY($2) AND is_deleted = FALSE`
+
+	// dedup key keeps scope in which link was created
+	updateWorkspaceURLReq = `UPDATE shortener_urls SET original = $3,
+	dedup_key = CASE WHEN dedup_key = original THEN $3 WHEN dedup_key = user_id || '|' || original THEN user_id || '|' || $3 END
+	WHERE workspace_id = $1 AND short = $2 AND is_deleted = FALSE`
+)
+
+const (
+	searchURLsReq = `SELECT short, original, user_id, COALESCE(workspace_id, ''), is_deleted, is_disabled,
+	title, created_at, redirect_status, cache_control, tracked FROM shortener_urls
+	WHERE ($1 = '' OR short = $1) AND ($2 = '' OR strpos(lower(original), lower($2)) > 0) AND ($3 = '' OR user_id = $3)
+	ORDER BY short LIMIT $4`
+	selectURLReq      = `SELECT short, original, user_id, COALESCE(workspace_id, ''), is_deleted, is_disabled, title, created_at, redirect_status, cache_control, tracked FROM shortener_urls WHERE short = $1`
+	selectURLsReq     = `SELECT short, original, user_id, COALESCE(workspace_id, ''), is_deleted, is_disabled, title, created_at, redirect_status, cache_control, tracked FROM shortener_urls WHERE short = ANY($1)`
+	setURLDisabledReq = `UPDATE shortener_urls SET is_disabled = $2 WHERE short = $1`
+	setURLRedirectReq = `UPDATE shortener_urls SET redirect_status = $2, cache_control = $3, tracked = $4 WHERE short = $1`
+	setURLTitleReq    = `UPDATE shortener_urls SET title = $2 WHERE short = $1`
+	reassignURLReq    = `UPDATE shortener_urls SET user_id = $2 WHERE short = $1`
+	hardDeleteURLReq  = `DELETE FROM shortener_urls WHERE short = $1`
+	insertAuditReq    = `INSERT INTO shortener_audit_log(id, actor_id, request_id, ip, action, short, before_value, after_value, details,
+	created_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`
+	selectAuditReq = `SELECT id, actor_id, request_id, ip, action, short, before_value, after_value, details, created_at
+	FROM shortener_audit_log
+	WHERE ($1::timestamptz IS NULL OR created_at >= $1) AND ($2::timestamptz IS NULL OR created_at < $2)
+	AND ($3 = '' OR actor_id = $3) AND ($4 = '' OR short = $4) AND ($5 = '' OR action = $5)
+	ORDER BY created_at DESC LIMIT $6`
+)
+
+const (
+	selectDeletedUserURLsReq = `SELECT short, original, COALESCE(deleted_at, now()) FROM shortener_urls
+	WHERE user_id = $1 AND workspace_id IS NULL AND is_deleted = TRUE ORDER BY deleted_at DESC`
+	restoreURLsReq = `UPDATE shortener_urls SET is_deleted = FALSE, deleted_at = NULL
+	WHERE user_id = $1 AND workspace_id IS NULL AND short = ANY($2) AND is_deleted = TRUE RETURNING short`
+	purgeDeletedURLsReq = `DELETE FROM shortener_urls WHERE is_deleted = TRUE AND (deleted_at IS NULL OR deleted_at < $1)`
+)
+
+const (
+	insertDeleteJobReq = `INSERT INTO shortener_delete_jobs (id, user_id, short, operation_id, attempts, next_attempt_at, created_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $7) ON CONFLICT (id) DO NOTHING`
+	claimDueDeleteJobsReq = `UPDATE shortener_delete_jobs SET next_attempt_at = $2
+	WHERE id IN (SELECT id FROM shortener_delete_jobs WHERE is_dead = FALSE AND next_attempt_at <= $1
+	ORDER BY created_at LIMIT $3 FOR UPDATE SKIP LOCKED)
+	RETURNING id, user_id, short, operation_id, attempts, next_attempt_at, last_error, is_dead, created_at`
+	updateDeleteJobReq = `UPDATE shortener_delete_jobs SET attempts = $2, next_attempt_at = $3, last_error = $4, is_dead = $5
+	WHERE id = $1`
+	deleteDeleteJobsReq     = `DELETE FROM shortener_delete_jobs WHERE id = ANY($1)`
+	selectDeadDeleteJobsReq = `SELECT id, user_id, short, operation_id, attempts, next_attempt_at, last_error, is_dead, created_at
+	FROM shortener_delete_jobs WHERE is_dead = TRUE ORDER BY created_at DESC LIMIT $1`
+)
+
+const (
+	insertDeleteOperationReq = `INSERT INTO shortener_delete_operations (id, user_id, created_at, updated_at) VALUES ($1, $2, $3, $4)`
+	insertDeleteResultReq    = `INSERT INTO shortener_delete_operation_urls (operation_id, short, status, error) VALUES ($1, $2, $3, $4)`
+	selectDeleteOperationReq = `SELECT id, user_id, created_at, updated_at FROM shortener_delete_operations WHERE id = $1`
+	selectDeleteResultsReq   = `SELECT short, status, error FROM shortener_delete_operation_urls WHERE operation_id = $1 ORDER BY short`
+	updateDeleteResultReq    = `UPDATE shortener_delete_operation_urls SET status = $3, error = $4 WHERE operation_id = $1 AND short = $2`
+	touchDeleteOperationReq  = `UPDATE shortener_delete_operations SET updated_at = now() WHERE id = $1`
+)
+
+const (
+	tryAdvisoryLockReq = `SELECT pg_try_advisory_lock($1)`
+	advisoryUnlockReq  = `SELECT pg_advisory_unlock($1)`
+	upsertLeaderReq    = `INSERT INTO shortener_leaders (name, instance_id, acquired_at, renewed_at) VALUES ($1, $2, now(), now())
+	ON CONFLICT (name) DO UPDATE SET instance_id = EXCLUDED.instance_id, acquired_at = now(), renewed_at = now()`
+	renewLeaderReq  = `UPDATE shortener_leaders SET renewed_at = now() WHERE name = $1 AND instance_id = $2`
+	deleteLeaderReq = `DELETE FROM shortener_leaders WHERE name = $1 AND instance_id = $2`
+	selectLeaderReq = `SELECT name, instance_id, acquired_at, renewed_at FROM shortener_leaders WHERE name = $1`
+)
+
+const notifyReq = `SELECT pg_notify($1, $2)`
+
+const (
+	scanURLsReq = `SELECT short, original, user_id, COALESCE(workspace_id, ''), is_deleted, deleted_at, is_disabled,
+	redirect_status, cache_control, tracked, title, created_at FROM shortener_urls
+	WHERE ($1 = '' OR (user_id = $1 AND workspace_id IS NULL)) AND short > $2 ORDER BY short LIMIT $3`
+	hasURLsTableReq = `SELECT to_regclass('shortener_urls') IS NOT NULL`
+	// links with existing short are skipped, taken dedup key and missing workspace are dropped
+	importURLsReq = `INSERT INTO shortener_urls(short, original, user_id, workspace_id, dedup_key, is_deleted, deleted_at, is_disabled,
+		redirect_status, cache_control, tracked, title, created_at)
+	SELECT t.short, t.original, t.user_id, w.id,
+		CASE WHEN EXISTS (SELECT 1 FROM shortener_urls o WHERE o.dedup_key = t.dedup_key) THEN NULL ELSE NULLIF(t.dedup_key, '') END,
+		t.is_deleted, t.deleted_at, t.is_disabled, t.redirect_status, t.cache_control, t.tracked,
+		t.title, t.created_at
+	FROM unnest($1::text[], $2::text[], $3::text[], $4::text[], $5::text[], $6::bool[], $7::timestamptz[], $8::bool[],
+		$9::smallint[], $10::text[], $11::bool[], $12::text[], $13::timestamptz[])
+		AS t(short, original, user_id, workspace_id, dedup_key, is_deleted, deleted_at, is_disabled, redirect_status, cache_control, tracked,
+			title, created_at)
+	LEFT JOIN shortener_workspaces w ON w.id = t.workspace_id
+	WHERE NOT EXISTS (SELECT 1 FROM shortener_urls o WHERE o.short = t.short)`
+)
+
+const (
+	// expired key is taken again, live key is not changed
+	insertIdempotencyKeyReq = `INSERT INTO shortener_idempotency_keys (user_id, key, request_hash, created_at, expires_at) VALUES ($1, $2, $3, $4, $5)
+	ON CONFLICT (user_id, key) DO UPDATE SET request_hash = EXCLUDED.request_hash, status = 0, content_type = '', body = '',
+		created_at = EXCLUDED.created_at, expires_at = EXCLUDED.expires_at
+	WHERE shortener_idempotency_keys.expires_at <= EXCLUDED.created_at`
+	selectIdempotencyKeyReq = `SELECT user_id, key, request_hash, status, content_type, body, created_at, expires_at
+	FROM shortener_idempotency_keys WHERE user_id = $1 AND key = $2`
+	deleteIdempotencyKeyReq = `DELETE FROM shortener_idempotency_keys WHERE user_id = $1 AND key = $2`
+	purgeIdempotencyKeysReq = `DELETE FROM shortener_idempotency_keys WHERE expires_at <= $1`
+	// key is completed only by request which reserved it
+	completeIdempotencyKeyReq = `UPDATE shortener_idempotency_keys SET status = $3, content_type = $4, body = $5, expires_at = $6
+	WHERE user_id = $1 AND key = $2 AND created_at = $7`
+)
+
+const (
+	insertSettingReq          = `INSERT INTO shortener_settings(name) VALUES ($1) ON CONFLICT (name) DO NOTHING`
+	selectSettingForUpdateReq = `SELECT value FROM shortener_settings WHERE name = $1 FOR UPDATE`
+	updateSettingReq          = `UPDATE shortener_settings SET value = $2 WHERE name = $1`
+	// owner of every dedup key in scope $1 is oldest link, links without creation time first
+	dedupOwnersCTE = `WITH owners AS (SELECT DISTINCT ON (k.key) s.short, k.key FROM shortener_urls s
+	CROSS JOIN LATERAL (SELECT CASE $1::text WHEN 'user' THEN s.user_id || '|' || s.original WHEN 'none' THEN NULL
+		ELSE s.original END AS key) k
+	WHERE k.key IS NOT NULL ORDER BY k.key, s.created_at NULLS FIRST, s.short)`
+	clearDedupKeysReq = dedupOwnersCTE + `
+	UPDATE shortener_urls u SET dedup_key = NULL
+	WHERE u.dedup_key IS NOT NULL AND NOT EXISTS (SELECT 1 FROM owners o WHERE o.short = u.short AND o.key = u.dedup_key)`
+	setDedupKeysReq = dedupOwnersCTE + `
+	UPDATE shortener_urls u SET dedup_key = o.key FROM owners o WHERE u.short = o.short AND u.dedup_key IS DISTINCT FROM o.key`
 )
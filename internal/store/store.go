@@ -1,45 +1,193 @@
 package store
 
 import (
+	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/hollgett/shortener.git/internal/logger"
 	"github.com/hollgett/shortener.git/internal/models"
 )
 
 type Store interface {
-	SaveShortURL(URL models.ShortenerURL) (string, error)
-	SaveShortURLs(URLs []models.ShortenerURL) ([]models.ShortenerURL, error)
-	GetOriginalURL(ShortLink string) (string, error)
+	// hot paths of create and redirect take context of request, other methods are limited by query timeout only
+	SaveShortURL(ctx context.Context, URL models.ShortenerURL) (string, error)
+	SaveShortURLs(ctx context.Context, URLs []models.ShortenerURL) ([]models.ShortenerURL, error)
 	GetUserURLs(userID string) ([]models.URLResponse, error)
 	DeleteURLs(URLs []models.DeleteURL) error
+	UserStore
+	APIKeyStore
+	WorkspaceStore
+	AdminStore
+	AuditStore
+	TrashStore
+	DeleteJobStore
+	DeleteOperationStore
+	LeaderStore
+	MigrationStore
+	IdempotencyStore
+	RedirectStore
+	GetStats() (models.Stats, error)
 	Ping() error
 	Close() error
 }
 
+// UserStore repository of registered users and their sessions
+type UserStore interface {
+	CreateUser(user models.User) error
+	GetUserByLogin(login string) (models.User, error)
+	GetUserByID(userID string) (models.User, error)
+	// move all URLs of one user to another, used for claim anonymous user into account
+	ReassignUserURLs(fromUserID, toUserID string) error
+	CreateSession(session models.Session) error
+	GetSession(sessionID string) (models.Session, error)
+	RevokeSession(sessionID string) error
+	RevokeUserSessions(userID string) error
+	CreateUserIdentity(identity models.UserIdentity) error
+	GetUserIdentity(issuer, subject string) (models.UserIdentity, error)
+}
+
 // NewStore return implementations of store if problem with init store close store and return errors.
-func NewStore(logger *logger.Logger, filePath, databaseDSN string) (Store, error) {
+func NewStore(logger *logger.Logger, filePath, databaseDSN string, poolCfg PoolConfig, dedup DedupScope) (Store, error) {
 	var store Store
 	switch {
 	case len(databaseDSN) != 0:
-		postgreSQL, err := NewPostgreSQLStore(logger, databaseDSN)
+		postgreSQL, err := NewPostgreSQLStore(logger, databaseDSN, poolCfg, dedup)
 		if err != nil {
 			return nil, fmt.Errorf("build postgres store error: %w", err)
 		}
 		store = postgreSQL
 		logger.Info("postgreSQL mode")
 	case len(strings.TrimSpace(filePath)) != 0:
-		fileStore, err := NewFileStore(filePath)
+		fileStore, err := NewFileStore(filePath, dedup)
 		if err != nil {
 			return nil, fmt.Errorf("build filestore error: %w", err)
 		}
 		store = fileStore
 		logger.Info("filestorage mode")
 	default:
-		store = NewInMemoryStore()
+		store = NewInMemoryStore(dedup)
 		logger.Info("in memory mode")
 	}
 
 	return store, nil
 }
+
+// APIKeyStore repository of users api keys, only hash of key is stored
+type APIKeyStore interface {
+	CreateAPIKey(key models.APIKey) error
+	GetAPIKey(keyID string) (models.APIKey, error)
+	GetUserAPIKeys(userID string) ([]models.APIKey, error)
+	RevokeAPIKey(userID, keyID string) error
+	TouchAPIKey(keyID string, usedAt time.Time) error
+}
+
+// WorkspaceStore repository of workspaces, members and links owned by workspaces
+type WorkspaceStore interface {
+	CreateWorkspace(workspace models.Workspace, owner models.WorkspaceMember) error
+	GetWorkspace(workspaceID string) (models.Workspace, error)
+	GetUserWorkspaces(userID string) ([]models.WorkspaceResponse, error)
+	GetWorkspaceMember(workspaceID, userID string) (models.WorkspaceMember, error)
+	GetWorkspaceMembers(workspaceID string) ([]models.WorkspaceMember, error)
+	// create member or update role of existing member
+	SaveWorkspaceMember(member models.WorkspaceMember) error
+	DeleteWorkspaceMember(workspaceID, userID string) error
+	GetWorkspaceURLs(workspaceID string) ([]models.URLResponse, error)
+	// move personal link of user to workspace
+	MoveURLToWorkspace(userID, shortURL, workspaceID string) error
+	UpdateWorkspaceURL(workspaceID, shortURL, originalURL string) error
+	DeleteWorkspaceURLs(workspaceID string, shortURLs []string) error
+}
+
+// AdminStore access to links of all users for moderation
+type AdminStore interface {
+	SearchURLs(filter models.AdminURLFilter) ([]models.AdminURL, error)
+	GetURL(shortURL string) (models.AdminURL, error)
+	// existing links of given short links in any order, missing ones are skipped
+	GetURLs(shortURLs []string) ([]models.AdminURL, error)
+	SetURLDisabled(shortURL string, disabled bool) error
+	ReassignURL(shortURL, userID string) error
+	// remove link completely, short link can be used again
+	HardDeleteURL(shortURL string) error
+}
+
+// RedirectStore redirect targets of links with their redirect policy and preview data
+type RedirectStore interface {
+	// return target of active link, deleted and disabled links return errors
+	GetRedirect(ctx context.Context, shortURL string) (models.Redirect, error)
+	SetURLRedirect(shortURL string, policy models.RedirectPolicy) error
+	SetURLTitle(shortURL, title string) error
+}
+
+// AuditStore append-only log of actions, records are never changed
+type AuditStore interface {
+	SaveAuditRecord(record models.AuditRecord) error
+	// newest records first
+	GetAuditRecords(filter models.AuditFilter) ([]models.AuditRecord, error)
+}
+
+// TrashStore soft-deleted personal links of users, workspace links are not listed or restored
+type TrashStore interface {
+	GetDeletedUserURLs(userID string) ([]models.DeletedURLResponse, error)
+	// clear delete flag of own deleted links, return restored short links
+	RestoreURLs(userID string, shortURLs []string) ([]string, error)
+	// hard delete links deleted before time, return count of purged links
+	PurgeDeletedURLs(deletedBefore time.Time) (int, error)
+}
+
+// DeleteJobStore durable queue of link deletes
+type DeleteJobStore interface {
+	SaveDeleteJobs(jobs []models.DeleteJob) error
+	// take pending jobs with next attempt not later than now, oldest first. Next attempt of taken jobs
+	// is moved to leaseUntil, so other instances skip them until they are done or lease is expired
+	ClaimDueDeleteJobs(now, leaseUntil time.Time, limit int) ([]models.DeleteJob, error)
+	// save attempts, next attempt, last error and dead flag of failed jobs
+	UpdateDeleteJobs(jobs []models.DeleteJob) error
+	// remove done jobs
+	RemoveDeleteJobs(IDs []string) error
+	// dead-letter jobs, newest first
+	GetDeadDeleteJobs(limit int) ([]models.DeleteJob, error)
+}
+
+// DeleteOperationStore delete requests of users with result of every link
+type DeleteOperationStore interface {
+	CreateDeleteOperation(operation models.DeleteOperation) error
+	GetDeleteOperation(operationID string) (models.DeleteOperation, error)
+	// set status and error of listed links of operation
+	SetDeleteResults(operationID string, results []models.DeleteResult) error
+}
+
+// MigrationStore bulk copy of links between backends
+type MigrationStore interface {
+	// personal links of user with short greater than afterShort ordered by short, empty userID is all links of all users
+	ScanURLs(userID, afterShort string, limit int) ([]models.ShortenerURL, error)
+	// save links as is, links with existing short are skipped, return count of saved links
+	ImportURLs(URLs []models.ShortenerURL) (int, error)
+}
+
+// IdempotencyStore saved responses of create requests repeated with same idempotency key
+type IdempotencyStore interface {
+	// reserve key before request is handled, return ErrIdempotencyKeyExists if key is saved and not expired
+	SaveIdempotencyKey(record models.IdempotencyRecord) error
+	GetIdempotencyKey(userID, key string) (models.IdempotencyRecord, error)
+	// save response and expiry of handled request, key reserved again by other request after lease is not changed
+	CompleteIdempotencyKey(record models.IdempotencyRecord) error
+	DeleteIdempotencyKey(userID, key string) error
+	PurgeIdempotencyKeys(expiredBefore time.Time) (int, error)
+}
+
+// LeaderStore locks for election of single instance which runs scheduled jobs
+type LeaderStore interface {
+	NewLeaderLock(name, instanceID string) LeaderLock
+	GetLeader(name string) (models.Leader, error)
+}
+
+// LeaderLock is held by one instance at the same time
+type LeaderLock interface {
+	// try take lock, return true if lock is held by this instance
+	TryLock(ctx context.Context) (bool, error)
+	// check that lock is still held and renew leader record, lock is lost on error
+	Renew(ctx context.Context) error
+	Unlock(ctx context.Context) error
+}
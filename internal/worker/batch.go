@@ -0,0 +1,143 @@
+package worker
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type BatchConfig struct {
+	// batch is handled when it reaches size or every interval
+	Size     int
+	Interval time.Duration
+	// capacity of queue, producers wait when it is full
+	Buffer int
+	// count of batches handled at the same time, queue is not read while all are busy
+	Concurrency int
+	Hooks       Hooks
+}
+
+// Batcher collect items of queue into batches and handle them with bounded concurrency.
+// On shutdown items left in queue are handled before Run returns.
+type Batcher[T any] struct {
+	name   string
+	cfg    BatchConfig
+	handle func(ctx context.Context, items []T) error
+	queue  chan T
+	sem    chan struct{}
+	wg     *sync.WaitGroup
+}
+
+func NewBatcher[T any](name string, cfg BatchConfig, handle func(ctx context.Context, items []T) error) *Batcher[T] {
+	if cfg.Size <= 0 {
+		cfg.Size = 1
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = time.Second
+	}
+	if cfg.Buffer < 0 {
+		cfg.Buffer = 0
+	}
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = 1
+	}
+	return &Batcher[T]{
+		name:   name,
+		cfg:    cfg,
+		handle: handle,
+		queue:  make(chan T, cfg.Buffer),
+		sem:    make(chan struct{}, cfg.Concurrency),
+		wg:     &sync.WaitGroup{},
+	}
+}
+
+func (b *Batcher[T]) Name() string {
+	return b.name
+}
+
+// Push add item to queue, wait while queue is full until context is done.
+func (b *Batcher[T]) Push(ctx context.Context, item T) error {
+	select {
+	case b.queue <- item:
+		return nil
+	default:
+	}
+	b.cfg.Hooks.backpressure(b.name)
+	select {
+	case b.queue <- item:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// TryPush add item to queue without wait, return false if queue is full.
+func (b *Batcher[T]) TryPush(item T) bool {
+	select {
+	case b.queue <- item:
+		return true
+	default:
+		return false
+	}
+}
+
+func (b *Batcher[T]) Run(ctx context.Context) {
+	ticker := time.NewTicker(b.cfg.Interval)
+	defer ticker.Stop()
+
+	batch := make([]T, 0, b.cfg.Size)
+	for {
+		select {
+		case <-ctx.Done():
+			// items left in queue are handled without cancel
+			b.drain(context.WithoutCancel(ctx), batch)
+			b.wg.Wait()
+			return
+		case item := <-b.queue:
+			batch = append(batch, item)
+			if len(batch) >= b.cfg.Size {
+				b.dispatch(ctx, batch)
+				batch = make([]T, 0, b.cfg.Size)
+			}
+		case <-ticker.C:
+			if len(batch) != 0 {
+				b.dispatch(ctx, batch)
+				batch = make([]T, 0, b.cfg.Size)
+			}
+		}
+	}
+}
+
+// handle batch and items left in queue
+func (b *Batcher[T]) drain(ctx context.Context, batch []T) {
+	for {
+		select {
+		case item := <-b.queue:
+			batch = append(batch, item)
+			if len(batch) >= b.cfg.Size {
+				b.dispatch(ctx, batch)
+				batch = make([]T, 0, b.cfg.Size)
+			}
+		default:
+			if len(batch) != 0 {
+				b.dispatch(ctx, batch)
+			}
+			return
+		}
+	}
+}
+
+// handle batch in goroutine, wait while all slots are busy
+func (b *Batcher[T]) dispatch(ctx context.Context, items []T) {
+	b.sem <- struct{}{}
+	b.wg.Add(1)
+	go func() {
+		defer func() {
+			<-b.sem
+			b.wg.Done()
+		}()
+		start := time.Now()
+		err := b.handle(ctx, items)
+		b.cfg.Hooks.run(b.name, len(items), time.Since(start), err)
+	}()
+}
@@ -1,7 +1,10 @@
 package worker
 
 import (
-	"sync"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
 	"time"
 
 	"github.com/hollgett/shortener.git/internal/logger"
@@ -10,93 +13,200 @@ import (
 )
 
 const (
-	lenBuf     = 10
+	lenBuf     = 1000
 	timePush   = 1 * time.Second
 	limitQueue = 100
+	// count of batches of deletes run at the same time
+	concurrency = 2
+	// failed job is moved to dead-letter list after this count of attempts
+	maxAttempts = 5
+	// delay before retry is doubled with every attempt
+	backoffBase = 1 * time.Second
+	backoffMax  = 5 * time.Minute
+	// claimed jobs are not taken by other instances for this time, jobs of crashed instance are run after it
+	claimLease = 1 * time.Minute
+	lenJobID   = 16
 )
 
 type StoreDeleteURLs interface {
 	DeleteURLs(URLs []models.DeleteURL) error
+	SaveDeleteJobs(jobs []models.DeleteJob) error
+	ClaimDueDeleteJobs(now, leaseUntil time.Time, limit int) ([]models.DeleteJob, error)
+	UpdateDeleteJobs(jobs []models.DeleteJob) error
+	RemoveDeleteJobs(IDs []string) error
+	SetDeleteResults(operationID string, results []models.DeleteResult) error
 }
 
+// DeleteWorker persist deletes as jobs and run them in batches.
+// Failed batches are retried with exponential backoff, jobs left by previous run are drained on start.
 type DeleteWorker struct {
-	logger   *logger.Logger
-	store    StoreDeleteURLs
-	DeleteCh chan models.DeleteURL
-	DoneCh   chan struct{}
-	queue    []models.DeleteURL
-	ticker   *time.Ticker
-	mu       *sync.Mutex
-	wg       *sync.WaitGroup
+	logger *logger.Logger
+	store  StoreDeleteURLs
+	// new jobs, claimed by this instance on save
+	batcher *Batcher[models.DeleteJob]
+	// retry of failed jobs and jobs left by stopped instances
+	dueJobs *ScheduledJob
 }
 
-func NewDeleteWorker(logger *logger.Logger, store StoreDeleteURLs) *DeleteWorker {
-	deleteCh := make(chan models.DeleteURL, lenBuf)
-
-	return &DeleteWorker{
-		logger:   logger,
-		store:    store,
-		DeleteCh: deleteCh,
-		DoneCh:   make(chan struct{}),
-		queue:    make([]models.DeleteURL, 0),
-		ticker:   time.NewTicker(timePush),
-		mu:       &sync.Mutex{},
-		wg:       &sync.WaitGroup{},
+// NewDeleteWorker build worker, due jobs left by other instances are run only by leader.
+func NewDeleteWorker(logger *logger.Logger, store StoreDeleteURLs, leader Leader, hooks Hooks) *DeleteWorker {
+	d := &DeleteWorker{
+		logger: logger,
+		store:  store,
 	}
+	d.batcher = NewBatcher("delete urls", BatchConfig{
+		Size:        limitQueue,
+		Interval:    timePush,
+		Buffer:      lenBuf,
+		Concurrency: concurrency,
+		Hooks:       hooks,
+	}, func(ctx context.Context, jobs []models.DeleteJob) error {
+		return d.run(jobs)
+	})
+	d.dueJobs = NewScheduledJob("retry delete jobs", ScheduleConfig{
+		Schedule:   Every(timePush),
+		RunAtStart: true,
+		Leader:     leader,
+		Hooks:      hooks,
+	}, func(ctx context.Context) error {
+		return d.runDue()
+	})
+	return d
 }
 
-func (d *DeleteWorker) Run() {
+// Jobs of worker for runner
+func (d *DeleteWorker) Jobs() []Job {
+	return []Job{d.batcher, d.dueJobs}
+}
+
+// Enqueue persist deletes as jobs and push them to queue, they are run in background after return.
+//
+// jobs are saved claimed by this instance. Jobs which are not queued until timePush are run by retry job
+// when claim is expired.
+func (d *DeleteWorker) Enqueue(ctx context.Context, URLs []models.DeleteURL) error {
+	now := time.Now().UTC()
+	jobs := make([]models.DeleteJob, len(URLs))
+	for i, url := range URLs {
+		jobs[i] = models.DeleteJob{
+			ID:            newJobID(),
+			UserID:        url.UserID,
+			ShortURL:      url.ShortURL,
+			OperationID:   url.OperationID,
+			NextAttemptAt: now.Add(claimLease),
+			CreatedAt:     now,
+		}
+	}
+	if err := d.store.SaveDeleteJobs(jobs); err != nil {
+		return fmt.Errorf("failed save delete jobs: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timePush)
+	defer cancel()
+	for i, job := range jobs {
+		if err := d.batcher.Push(ctx, job); err != nil {
+			d.logger.Info("delete queue is full", zap.Int("left", len(jobs)-i), zap.Error(err))
+			break
+		}
+	}
+	return nil
+}
+
+// claim and run due jobs in batches until no due jobs left
+func (d *DeleteWorker) runDue() error {
 	for {
-		select {
-		case <-d.DoneCh:
-			return
-		case url := <-d.DeleteCh:
-			d.add(url)
-		case <-d.ticker.C:
-			d.flush()
+		now := time.Now().UTC()
+		jobs, err := d.store.ClaimDueDeleteJobs(now, now.Add(claimLease), limitQueue)
+		if err != nil {
+			return fmt.Errorf("failed claim due delete jobs: %w", err)
+		}
+		if len(jobs) == 0 {
+			return nil
+		}
+		if err := d.run(jobs); err != nil {
+			return err
+		}
+		if len(jobs) < limitQueue {
+			return nil
 		}
 	}
 }
 
-func (d *DeleteWorker) add(url models.DeleteURL) {
-	d.mu.Lock()
-	defer d.mu.Unlock()
+// delete links of claimed jobs, failed jobs are scheduled for retry
+func (d *DeleteWorker) run(jobs []models.DeleteJob) error {
+	toDelete := make([]models.DeleteURL, len(jobs))
+	for i, job := range jobs {
+		toDelete[i] = models.DeleteURL{UserID: job.UserID, ShortURL: job.ShortURL}
+	}
+	if err := d.store.DeleteURLs(toDelete); err != nil {
+		d.retry(jobs, err)
+		return fmt.Errorf("failed delete urls: %w", err)
+	}
 
-	d.queue = append(d.queue, url)
-	if len(d.queue) >= limitQueue {
-		d.flush()
+	IDs := make([]string, len(jobs))
+	for i, job := range jobs {
+		IDs[i] = job.ID
 	}
+	d.report(jobs, models.DeleteResultDeleted, "")
+	if err := d.store.RemoveDeleteJobs(IDs); err != nil {
+		// urls are deleted already, jobs will be run again without changes when claim is expired
+		return fmt.Errorf("failed remove delete jobs: %w", err)
+	}
+	return nil
 }
 
-func (d *DeleteWorker) flush() {
-	if len(d.queue) == 0 {
+// schedule next attempt of failed jobs or move them to dead-letter list
+func (d *DeleteWorker) retry(jobs []models.DeleteJob, cause error) {
+	now := time.Now().UTC()
+	dead := make([]models.DeleteJob, 0)
+	for i := range jobs {
+		jobs[i].Attempts++
+		jobs[i].LastError = cause.Error()
+		jobs[i].NextAttemptAt = now.Add(backoff(jobs[i].Attempts))
+		if jobs[i].Attempts >= maxAttempts {
+			jobs[i].Dead = true
+			dead = append(dead, jobs[i])
+			d.logger.Error("delete job is dead", zap.String("id", jobs[i].ID), zap.String("short", jobs[i].ShortURL),
+				zap.String("user id", jobs[i].UserID), zap.Error(cause))
+		}
+	}
+	if err := d.store.UpdateDeleteJobs(jobs); err != nil {
+		d.logger.Info("update delete jobs", zap.Error(err))
 		return
 	}
-	d.flushLocker()
+	d.report(dead, models.DeleteResultFailed, cause.Error())
 }
 
-func (d *DeleteWorker) flushLocker() {
-	d.mu.Lock()
-	defer d.mu.Unlock()
-	toDelete := make([]models.DeleteURL, len(d.queue))
-	copy(toDelete, d.queue)
-	d.queue = d.queue[:0]
-
-	d.wg.Add(1)
-	go func() {
-		defer d.wg.Done()
-		err := d.store.DeleteURLs(toDelete)
-		if err != nil {
-			d.logger.Info("delete flush", zap.Error(err))
+// set result of jobs to their delete operations, errors are only logged
+func (d *DeleteWorker) report(jobs []models.DeleteJob, status, errMsg string) {
+	byOperation := make(map[string][]models.DeleteResult)
+	for _, job := range jobs {
+		if len(job.OperationID) == 0 {
+			continue
+		}
+		byOperation[job.OperationID] = append(byOperation[job.OperationID], models.DeleteResult{
+			ShortURL: job.ShortURL,
+			Status:   status,
+			Error:    errMsg,
+		})
+	}
+	for operationID, results := range byOperation {
+		if err := d.store.SetDeleteResults(operationID, results); err != nil {
+			d.logger.Info("set delete results", zap.String("operation id", operationID), zap.Error(err))
 		}
-	}()
+	}
 }
 
-func (d *DeleteWorker) ShutDown() {
-	d.ticker.Stop()
+// delay before next attempt, doubled with every attempt up to backoffMax
+func backoff(attempts int) time.Duration {
+	delay := backoffBase
+	for i := 1; i < attempts && delay < backoffMax; i++ {
+		delay *= 2
+	}
+	return min(delay, backoffMax)
+}
 
-	d.flush()
-	d.wg.Wait()
-	close(d.DeleteCh)
-	close(d.DoneCh)
+func newJobID() string {
+	b := make([]byte, lenJobID)
+	rand.Read(b)
+	return hex.EncodeToString(b)
 }
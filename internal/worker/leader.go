@@ -0,0 +1,110 @@
+package worker
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/hollgett/shortener.git/internal/logger"
+	"go.uber.org/zap"
+)
+
+// interval of attempts to take lock and renew of held lock
+const timeElection = 5 * time.Second
+
+// Leader report that instance is leader now
+type Leader interface {
+	IsLeader() bool
+	// Elected is closed when first election is over, before it IsLeader is false on every instance
+	Elected() <-chan struct{}
+}
+
+type LeaderLock interface {
+	TryLock(ctx context.Context) (bool, error)
+	Renew(ctx context.Context) error
+	Unlock(ctx context.Context) error
+}
+
+// Elector campaign for leader lock, lock is taken over by another instance when leader stops or loses connection.
+type Elector struct {
+	logger     *logger.Logger
+	lock       LeaderLock
+	name       string
+	instanceID string
+	leader     *atomic.Bool
+	elected    chan struct{}
+}
+
+func NewElector(logger *logger.Logger, lock LeaderLock, name, instanceID string) *Elector {
+	return &Elector{
+		logger:     logger,
+		lock:       lock,
+		name:       name,
+		instanceID: instanceID,
+		leader:     &atomic.Bool{},
+		elected:    make(chan struct{}),
+	}
+}
+
+func (e *Elector) Name() string {
+	return "leader election"
+}
+
+// LockName is name of lock which instances campaign for
+func (e *Elector) LockName() string {
+	return e.name
+}
+
+func (e *Elector) InstanceID() string {
+	return e.instanceID
+}
+
+func (e *Elector) IsLeader() bool {
+	return e.leader.Load()
+}
+
+func (e *Elector) Elected() <-chan struct{} {
+	return e.elected
+}
+
+func (e *Elector) Run(ctx context.Context) {
+	ticker := time.NewTicker(timeElection)
+	defer ticker.Stop()
+
+	e.campaign(ctx)
+	close(e.elected)
+	for {
+		select {
+		case <-ctx.Done():
+			if e.leader.Swap(false) {
+				if err := e.lock.Unlock(context.WithoutCancel(ctx)); err != nil {
+					e.logger.Info("leader unlock", zap.Error(err))
+				}
+			}
+			return
+		case <-ticker.C:
+			e.campaign(ctx)
+		}
+	}
+}
+
+// take lock if it is free or renew held lock
+func (e *Elector) campaign(ctx context.Context) {
+	if e.leader.Load() {
+		if err := e.lock.Renew(ctx); err != nil {
+			e.leader.Store(false)
+			e.logger.Info("leadership lost", zap.String("lock", e.name), zap.Error(err))
+		}
+		return
+	}
+
+	locked, err := e.lock.TryLock(ctx)
+	if err != nil {
+		e.logger.Info("leader try lock", zap.Error(err))
+		return
+	}
+	if locked {
+		e.leader.Store(true)
+		e.logger.Info("leadership taken", zap.String("lock", e.name), zap.String("instance", e.instanceID))
+	}
+}
@@ -0,0 +1,55 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/hollgett/shortener.git/internal/logger"
+	"go.uber.org/zap"
+)
+
+type ServicePurgeURLs interface {
+	PurgeDeletedURLs(retention time.Duration) (int, error)
+}
+
+// NewPurgeJob hard delete links which were deleted longer than retention ago, run by schedule on leader.
+func NewPurgeJob(logger *logger.Logger, service ServicePurgeURLs, retention time.Duration, schedule Schedule,
+	leader Leader, hooks Hooks) *ScheduledJob {
+	return NewScheduledJob("purge deleted urls", ScheduleConfig{
+		Schedule:   schedule,
+		RunAtStart: true,
+		Leader:     leader,
+		Hooks:      hooks,
+	}, func(ctx context.Context) error {
+		purged, err := service.PurgeDeletedURLs(retention)
+		if err != nil {
+			return err
+		}
+		if purged != 0 {
+			logger.Info("purge deleted urls", zap.Int("count", purged))
+		}
+		return nil
+	})
+}
+
+type ServicePurgeIdempotencyKeys interface {
+	PurgeIdempotencyKeys() (int, error)
+}
+
+// NewIdempotencyPurgeJob delete expired idempotency keys every hour on leader.
+func NewIdempotencyPurgeJob(logger *logger.Logger, service ServicePurgeIdempotencyKeys, leader Leader, hooks Hooks) *ScheduledJob {
+	return NewScheduledJob("purge idempotency keys", ScheduleConfig{
+		Schedule: Every(time.Hour),
+		Leader:   leader,
+		Hooks:    hooks,
+	}, func(ctx context.Context) error {
+		purged, err := service.PurgeIdempotencyKeys()
+		if err != nil {
+			return err
+		}
+		if purged != 0 {
+			logger.Info("purge idempotency keys", zap.Int("count", purged))
+		}
+		return nil
+	})
+}
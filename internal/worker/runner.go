@@ -0,0 +1,85 @@
+package worker
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/hollgett/shortener.git/internal/logger"
+	"go.uber.org/zap"
+)
+
+// Job is background task, Run returns when context is canceled and job is finished.
+type Job interface {
+	Name() string
+	Run(ctx context.Context)
+}
+
+// Hooks are called by jobs to collect metrics, nil hook is skipped.
+type Hooks struct {
+	// called after every run of batch or scheduled job, items is zero for scheduled jobs
+	OnRun func(job string, items int, took time.Duration, err error)
+	// called when producer waits because queue of job is full
+	OnBackpressure func(job string)
+}
+
+func (h Hooks) run(job string, items int, took time.Duration, err error) {
+	if h.OnRun != nil {
+		h.OnRun(job, items, took, err)
+	}
+}
+
+func (h Hooks) backpressure(job string) {
+	if h.OnBackpressure != nil {
+		h.OnBackpressure(job)
+	}
+}
+
+// LogHooks write failed runs and backpressure to log.
+func LogHooks(logger *logger.Logger) Hooks {
+	return Hooks{
+		OnRun: func(job string, items int, took time.Duration, err error) {
+			if err != nil {
+				logger.Info("job failed", zap.String("job", job), zap.Int("items", items),
+					zap.Duration("took", took), zap.Error(err))
+			}
+		},
+		OnBackpressure: func(job string) {
+			logger.Info("job queue is full", zap.String("job", job))
+		},
+	}
+}
+
+// Runner start jobs and wait them on shutdown.
+type Runner struct {
+	jobs []Job
+	wg   *sync.WaitGroup
+}
+
+func NewRunner() *Runner {
+	return &Runner{
+		jobs: make([]Job, 0),
+		wg:   &sync.WaitGroup{},
+	}
+}
+
+// Add jobs, must be called before Start.
+func (r *Runner) Add(jobs ...Job) {
+	r.jobs = append(r.jobs, jobs...)
+}
+
+// Start run every job in own goroutine until context is canceled.
+func (r *Runner) Start(ctx context.Context) {
+	for _, job := range r.jobs {
+		r.wg.Add(1)
+		go func(job Job) {
+			defer r.wg.Done()
+			job.Run(ctx)
+		}(job)
+	}
+}
+
+// Wait for jobs to finish after context is canceled.
+func (r *Runner) Wait() {
+	r.wg.Wait()
+}
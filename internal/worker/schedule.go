@@ -0,0 +1,211 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule return next time of run after given time.
+type Schedule interface {
+	Next(after time.Time) time.Time
+}
+
+type every time.Duration
+
+// Every run job with fixed interval.
+func Every(interval time.Duration) Schedule {
+	return every(interval)
+}
+
+func (e every) Next(after time.Time) time.Time {
+	return after.Add(time.Duration(e))
+}
+
+// cron schedule, field is set of allowed values
+type cron struct {
+	minute, hour, dom, month, dow map[int]struct{}
+	// day of month or week is restricted, if both are then any of them matches
+	domAny, dowAny bool
+}
+
+// limit of search of next run, schedule like "0 0 30 2 *" never matches
+const cronSearchLimit = 5 * 366 * 24 * time.Hour
+
+// ParseCron parse schedule in cron format "minute hour day-of-month month day-of-week" in local time,
+// every field is "*", number, range "1-5", step "*/15" or "1-30/5", or list of them separated by comma.
+func ParseCron(spec string) (Schedule, error) {
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("invalid cron %q: 5 fields expected", spec)
+	}
+	bounds := [5][2]int{{0, 59}, {0, 23}, {1, 31}, {1, 12}, {0, 6}}
+	sets := make([]map[int]struct{}, 5)
+	for i, field := range fields {
+		set, err := parseCronField(field, bounds[i][0], bounds[i][1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid cron %q: %w", spec, err)
+		}
+		sets[i] = set
+	}
+	return &cron{
+		minute: sets[0],
+		hour:   sets[1],
+		dom:    sets[2],
+		month:  sets[3],
+		dow:    sets[4],
+		domAny: fields[2] == "*",
+		dowAny: fields[4] == "*",
+	}, nil
+}
+
+func parseCronField(field string, low, high int) (map[int]struct{}, error) {
+	set := make(map[int]struct{})
+	for _, part := range strings.Split(field, ",") {
+		step := 1
+		if value, stepValue, ok := strings.Cut(part, "/"); ok {
+			var err error
+			if step, err = strconv.Atoi(stepValue); err != nil || step <= 0 {
+				return nil, fmt.Errorf("invalid step %q", part)
+			}
+			part = value
+		}
+
+		from, to := low, high
+		switch value, toValue, isRange := strings.Cut(part, "-"); {
+		case part == "*":
+		case isRange:
+			var errFrom, errTo error
+			from, errFrom = strconv.Atoi(value)
+			to, errTo = strconv.Atoi(toValue)
+			if errFrom != nil || errTo != nil {
+				return nil, fmt.Errorf("invalid range %q", part)
+			}
+		default:
+			n, err := strconv.Atoi(part)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value %q", part)
+			}
+			from, to = n, n
+			// "5/10" means from 5 to end with step
+			if step != 1 {
+				to = high
+			}
+		}
+		if from < low || to > high || from > to {
+			return nil, fmt.Errorf("value %q out of range %d-%d", part, low, high)
+		}
+		for n := from; n <= to; n += step {
+			set[n] = struct{}{}
+		}
+	}
+	return set, nil
+}
+
+func (c *cron) Next(after time.Time) time.Time {
+	t := after.Truncate(time.Minute).Add(time.Minute)
+	limit := after.Add(cronSearchLimit)
+	for t.Before(limit) {
+		if _, ok := c.month[int(t.Month())]; !ok {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !c.matchDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if _, ok := c.hour[t.Hour()]; !ok {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if _, ok := c.minute[t.Minute()]; !ok {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	// never matches, job is not run
+	return time.Time{}
+}
+
+func (c *cron) matchDay(t time.Time) bool {
+	_, dom := c.dom[t.Day()]
+	_, dow := c.dow[int(t.Weekday())]
+	switch {
+	case c.domAny && c.dowAny:
+		return true
+	case c.domAny:
+		return dow
+	case c.dowAny:
+		return dom
+	default:
+		return dom || dow
+	}
+}
+
+type ScheduleConfig struct {
+	Schedule Schedule
+	// run job once on start, before first scheduled time. Job with leader waits for first election
+	RunAtStart bool
+	// run only while instance is leader, nil runs on every instance
+	Leader Leader
+	Hooks  Hooks
+}
+
+// ScheduledJob run function by schedule, runs never overlap.
+type ScheduledJob struct {
+	name string
+	cfg  ScheduleConfig
+	run  func(ctx context.Context) error
+}
+
+func NewScheduledJob(name string, cfg ScheduleConfig, run func(ctx context.Context) error) *ScheduledJob {
+	return &ScheduledJob{
+		name: name,
+		cfg:  cfg,
+		run:  run,
+	}
+}
+
+func (s *ScheduledJob) Name() string {
+	return s.name
+}
+
+func (s *ScheduledJob) Run(ctx context.Context) {
+	if s.cfg.RunAtStart {
+		if s.cfg.Leader != nil {
+			select {
+			case <-ctx.Done():
+				return
+			case <-s.cfg.Leader.Elected():
+			}
+		}
+		s.exec(ctx)
+	}
+	for {
+		next := s.cfg.Schedule.Next(time.Now())
+		if next.IsZero() {
+			<-ctx.Done()
+			return
+		}
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+			s.exec(ctx)
+		}
+	}
+}
+
+func (s *ScheduledJob) exec(ctx context.Context) {
+	if s.cfg.Leader != nil && !s.cfg.Leader.IsLeader() {
+		return
+	}
+	start := time.Now()
+	err := s.run(ctx)
+	s.cfg.Hooks.run(s.name, 0, time.Since(start), err)
+}
@@ -1,54 +1,83 @@
 package autoloaderenv
 
 import (
-	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
 	"os"
 )
 
-const fileNameEnv = ".env"
+// default files, later file has priority over earlier
+var defaultFiles = []string{".env", ".env.local"}
 
-// LoadEnv set env.
+// LoadEnv set env from dotenv files.
 //
-// opening default file ".env", parse and set env.
-func LoadEnv() {
-	src, err := getFileData()
+// by default opening ".env" and ".env.local", missing files are skipped. Values from later files
+// override earlier, but already existing process env is never overridden.
+func LoadEnv(filenames ...string) error {
+	return load(false, filenames)
+}
+
+// Overload set env from dotenv files same as LoadEnv, but override existing process env.
+func Overload(filenames ...string) error {
+	return load(true, filenames)
+}
+
+// Read parse dotenv files and return merged result without setting env.
+func Read(filenames ...string) (map[string]string, error) {
+	return read(false, filenames)
+}
+
+// read parse files, variables of existing process env are interpolated before values of files if envFirst
+func read(envFirst bool, filenames []string) (map[string]string, error) {
+	if len(filenames) == 0 {
+		filenames = defaultFiles
+	}
+	envs := make(map[string]string)
+	for _, filename := range filenames {
+		src, err := getFileData(filename)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return nil, fmt.Errorf("failed read %s: %w", filename, err)
+		}
+
+		if err := parseInto(src, envs, envFirst); err != nil {
+			var parseErr *ParseError
+			if errors.As(err, &parseErr) {
+				parseErr.File = filename
+			}
+			return nil, err
+		}
+	}
+	return envs, nil
+}
+
+func load(override bool, filenames []string) error {
+	// not overridden process env keeps its value, so references to it must resolve to same value
+	envs, err := read(!override, filenames)
 	if err != nil {
-		return
+		return err
 	}
-	setEnv(parseByte(src))
+	return setEnv(envs, override)
 }
 
-func getFileData() ([]byte, error) {
-	src, err := os.ReadFile(fileNameEnv)
+func getFileData(filename string) ([]byte, error) {
+	src, err := os.ReadFile(filename)
 	if err != nil {
 		return nil, err
 	}
 	return src, nil
 }
 
-func setEnv(envs map[string]string) {
-	if len(envs) == 0 {
-		return
-	}
+func setEnv(envs map[string]string, override bool) error {
 	for k, v := range envs {
-		os.Setenv(k, v)
-	}
-}
-
-func parseByte(src []byte) map[string]string {
-	buf := bytes.Split(bytes.ReplaceAll(src, []byte("\r\n"), []byte("\n")), []byte("\n"))
-	envs := make(map[string]string)
-	for _, v := range buf {
-		if len(v) == 0 || v[0] == '#' {
+		if _, ok := os.LookupEnv(k); ok && !override {
 			continue
 		}
-		b := bytes.SplitN(v, []byte("="), 2)
-		if len(b) == 2 {
-			envs[string(b[0])] = string(b[1])
-		} else {
-			envs[string(b[0])] = ""
+		if err := os.Setenv(k, v); err != nil {
+			return fmt.Errorf("failed set env %s: %w", k, err)
 		}
-
 	}
-	return envs
+	return nil
 }
@@ -0,0 +1,333 @@
+package autoloaderenv
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ParseError describe problem in dotenv source with line number where it happened.
+type ParseError struct {
+	File string
+	Line int
+	Msg  string
+}
+
+func (e *ParseError) Error() string {
+	if e.File == "" {
+		return fmt.Sprintf("line %d: %s", e.Line, e.Msg)
+	}
+	return fmt.Sprintf("%s:%d: %s", e.File, e.Line, e.Msg)
+}
+
+// Parse read dotenv source and return map of key and value.
+//
+// supports comments, "export" prefix, single quoted (raw) values, double quoted values with escapes,
+// multiline quoted values and ${VAR} / $VAR interpolation. Interpolation looks up keys parsed earlier,
+// then process environment.
+func Parse(src []byte) (map[string]string, error) {
+	envs := make(map[string]string)
+	if err := parseInto(src, envs, false); err != nil {
+		return nil, err
+	}
+	return envs, nil
+}
+
+// parseInto parse src and put result to envs. Interpolation looks up envs before process environment,
+// or process environment first if envFirst, same as it wins when values are set without override.
+func parseInto(src []byte, envs map[string]string, envFirst bool) error {
+	p := parser{
+		src:  bytes.ReplaceAll(src, []byte("\r\n"), []byte("\n")),
+		line: 1,
+		envs: envs,
+		lookup: func(key string) (string, bool) {
+			if envFirst {
+				if v, ok := os.LookupEnv(key); ok {
+					return v, true
+				}
+			}
+			if v, ok := envs[key]; ok {
+				return v, true
+			}
+			return os.LookupEnv(key)
+		},
+	}
+	return p.parse()
+}
+
+type parser struct {
+	src    []byte
+	pos    int
+	line   int
+	envs   map[string]string
+	lookup func(string) (string, bool)
+}
+
+func (p *parser) errorf(line int, format string, args ...any) error {
+	return &ParseError{Line: line, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (p *parser) eof() bool {
+	return p.pos >= len(p.src)
+}
+
+func (p *parser) peek() byte {
+	return p.src[p.pos]
+}
+
+func (p *parser) next() byte {
+	c := p.src[p.pos]
+	p.pos++
+	if c == '\n' {
+		p.line++
+	}
+	return c
+}
+
+// skip spaces and tabs, but not new lines
+func (p *parser) skipBlank() {
+	for !p.eof() && (p.peek() == ' ' || p.peek() == '\t') {
+		p.pos++
+	}
+}
+
+// skip rest of current line with new line symbol
+func (p *parser) skipLine() {
+	for !p.eof() && p.next() != '\n' {
+	}
+}
+
+func (p *parser) parse() error {
+	for !p.eof() {
+		p.skipBlank()
+		if p.eof() {
+			break
+		}
+		switch p.peek() {
+		case '\n':
+			p.next()
+			continue
+		case '#':
+			p.skipLine()
+			continue
+		}
+
+		if err := p.parseStatement(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *parser) parseStatement() error {
+	line := p.line
+	key, err := p.parseKey()
+	if err != nil {
+		return err
+	}
+	if key == "export" {
+		p.skipBlank()
+		if !p.eof() && p.peek() != '=' && p.peek() != '\n' {
+			if key, err = p.parseKey(); err != nil {
+				return err
+			}
+		}
+	}
+
+	p.skipBlank()
+	if p.eof() || p.peek() != '=' {
+		return p.errorf(line, "missing '=' after key %q", key)
+	}
+	p.next()
+	p.skipBlank()
+
+	value, err := p.parseValue()
+	if err != nil {
+		return err
+	}
+	p.envs[key] = value
+	return nil
+}
+
+func (p *parser) parseKey() (string, error) {
+	start := p.pos
+	for !p.eof() && isKeyChar(p.peek()) {
+		p.pos++
+	}
+	if start == p.pos {
+		if p.eof() || p.peek() == '\n' {
+			return "", p.errorf(p.line, "unexpected end of line, expected key")
+		}
+		return "", p.errorf(p.line, "unexpected character %q in key", p.peek())
+	}
+	key := string(p.src[start:p.pos])
+	if key[0] >= '0' && key[0] <= '9' {
+		return "", p.errorf(p.line, "key %q must not start with digit", key)
+	}
+	return key, nil
+}
+
+func isKeyChar(c byte) bool {
+	return c == '_' || c == '.' ||
+		(c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+func (p *parser) parseValue() (string, error) {
+	if p.eof() {
+		return "", nil
+	}
+	switch p.peek() {
+	case '\'':
+		return p.parseSingleQuoted()
+	case '"':
+		return p.parseDoubleQuoted()
+	default:
+		return p.parseUnquoted()
+	}
+}
+
+// single quoted value is raw, without escapes and interpolation, could be multiline
+func (p *parser) parseSingleQuoted() (string, error) {
+	line := p.line
+	p.next()
+	start := p.pos
+	for !p.eof() && p.peek() != '\'' {
+		p.next()
+	}
+	if p.eof() {
+		return "", p.errorf(line, "unterminated single quoted value")
+	}
+	value := string(p.src[start:p.pos])
+	p.next()
+	return value, p.endOfValue()
+}
+
+// double quoted value supports escapes and interpolation, could be multiline
+func (p *parser) parseDoubleQuoted() (string, error) {
+	line := p.line
+	p.next()
+	var value strings.Builder
+	for {
+		if p.eof() {
+			return "", p.errorf(line, "unterminated double quoted value")
+		}
+		c := p.next()
+		switch c {
+		case '"':
+			return value.String(), p.endOfValue()
+		case '\\':
+			if p.eof() {
+				return "", p.errorf(line, "unterminated double quoted value")
+			}
+			esc := p.next()
+			switch esc {
+			case 'n':
+				value.WriteByte('\n')
+			case 'r':
+				value.WriteByte('\r')
+			case 't':
+				value.WriteByte('\t')
+			case '\\', '"', '$', '\'':
+				value.WriteByte(esc)
+			case '\n':
+				// line continuation
+			default:
+				value.WriteByte('\\')
+				value.WriteByte(esc)
+			}
+		case '$':
+			if err := p.expand(&value); err != nil {
+				return "", err
+			}
+		default:
+			value.WriteByte(c)
+		}
+	}
+}
+
+// unquoted value ends on new line or inline comment, trailing spaces are trimmed
+func (p *parser) parseUnquoted() (string, error) {
+	var value strings.Builder
+	for !p.eof() && p.peek() != '\n' {
+		c := p.peek()
+		if c == '#' && (value.Len() == 0 || isBlank(value.String()[value.Len()-1])) {
+			p.skipLine()
+			break
+		}
+		p.next()
+		if c == '$' {
+			if err := p.expand(&value); err != nil {
+				return "", err
+			}
+			continue
+		}
+		value.WriteByte(c)
+	}
+	return strings.TrimRight(value.String(), " \t"), nil
+}
+
+func isBlank(c byte) bool {
+	return c == ' ' || c == '\t'
+}
+
+// after closing quote allowed only blanks and comment
+func (p *parser) endOfValue() error {
+	p.skipBlank()
+	if p.eof() {
+		return nil
+	}
+	switch p.peek() {
+	case '\n':
+		p.next()
+		return nil
+	case '#':
+		p.skipLine()
+		return nil
+	}
+	return p.errorf(p.line, "unexpected character %q after quoted value", p.peek())
+}
+
+// expand variable after '$', supports ${VAR}, ${VAR:-default} and $VAR forms
+func (p *parser) expand(value *strings.Builder) error {
+	if p.eof() {
+		value.WriteByte('$')
+		return nil
+	}
+	if p.peek() == '{' {
+		line := p.line
+		p.next()
+		start := p.pos
+		for !p.eof() && p.peek() != '}' && p.peek() != '\n' {
+			p.pos++
+		}
+		if p.eof() || p.peek() != '}' {
+			return p.errorf(line, "unterminated variable reference")
+		}
+		expr := string(p.src[start:p.pos])
+		p.next()
+
+		name, def, hasDef := strings.Cut(expr, ":-")
+		if name == "" {
+			return p.errorf(line, "empty variable reference")
+		}
+		v, ok := p.lookup(name)
+		if (!ok || v == "") && hasDef {
+			v = def
+		}
+		value.WriteString(v)
+		return nil
+	}
+
+	start := p.pos
+	for !p.eof() && (p.peek() == '_' || isKeyChar(p.peek()) && p.peek() != '.') {
+		p.pos++
+	}
+	if start == p.pos {
+		value.WriteByte('$')
+		return nil
+	}
+	v, _ := p.lookup(string(p.src[start:p.pos]))
+	value.WriteString(v)
+	return nil
+}
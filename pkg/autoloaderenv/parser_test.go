@@ -0,0 +1,171 @@
+package autoloaderenv
+
+import (
+	"errors"
+	"maps"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	t.Setenv("AUTOLOADERENV_TEST_HOST", "process")
+
+	tests := []struct {
+		name string
+		src  string
+		want map[string]string
+	}{
+		{
+			name: "unquoted with comments and blank lines",
+			src:  "# comment\n\nA=1\nB = two words  # inline\nC=a#b\n",
+			want: map[string]string{"A": "1", "B": "two words", "C": "a#b"},
+		},
+		{
+			name: "empty value",
+			src:  "A=\nB=",
+			want: map[string]string{"A": "", "B": ""},
+		},
+		{
+			name: "crlf line endings",
+			src:  "A=1\r\nB=2\r\n",
+			want: map[string]string{"A": "1", "B": "2"},
+		},
+		{
+			name: "export prefix",
+			src:  "export A=1\nexport=2\n",
+			want: map[string]string{"A": "1", "export": "2"},
+		},
+		{
+			name: "single quoted is raw",
+			src:  `A='x\n $B ${B}' # comment`,
+			want: map[string]string{"A": `x\n $B ${B}`},
+		},
+		{
+			name: "double quoted escapes",
+			src:  `A="tab\tnew\nquote\" slash\\ dollar\$ other\q"`,
+			want: map[string]string{"A": "tab\tnew\nquote\" slash\\ dollar$ other\\q"},
+		},
+		{
+			name: "multiline quoted values",
+			src:  "A=\"one\ntwo\"\nB='three\nfour'\nC=\"line \\\ncontinued\"\n",
+			want: map[string]string{"A": "one\ntwo", "B": "three\nfour", "C": "line continued"},
+		},
+		{
+			name: "interpolation of earlier keys",
+			src:  "A=x\nB=${A}-$A\nC=\"${A}y\"\n",
+			want: map[string]string{"A": "x", "B": "x-x", "C": "xy"},
+		},
+		{
+			name: "interpolation defaults",
+			src:  "A=\nB=${A:-def}\nC=${AUTOLOADERENV_TEST_MISSING:-miss}\nD=${AUTOLOADERENV_TEST_MISSING}\n",
+			want: map[string]string{"A": "", "B": "def", "C": "miss", "D": ""},
+		},
+		{
+			name: "interpolation of process env",
+			src:  "A=${AUTOLOADERENV_TEST_HOST}\n",
+			want: map[string]string{"A": "process"},
+		},
+		{
+			name: "file value wins in interpolation",
+			src:  "AUTOLOADERENV_TEST_HOST=file\nA=${AUTOLOADERENV_TEST_HOST}\n",
+			want: map[string]string{"AUTOLOADERENV_TEST_HOST": "file", "A": "file"},
+		},
+		{
+			name: "lone dollar",
+			src:  "A=$\nB=5$ off\n",
+			want: map[string]string{"A": "$", "B": "5$ off"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse([]byte(tt.src))
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if !maps.Equal(got, tt.want) {
+				t.Errorf("Parse() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseError(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		line int
+	}{
+		{name: "missing equal", src: "A=1\nB\n", line: 2},
+		{name: "key starts with digit", src: "1A=1", line: 1},
+		{name: "invalid key character", src: "A=1\n\nA-B=2", line: 3},
+		{name: "unterminated single quote", src: "A=1\nB='x\ny\n", line: 2},
+		{name: "unterminated double quote", src: "A=\"x\n\ny", line: 1},
+		{name: "text after quoted value", src: "A=1\nB=\"x\" y", line: 2},
+		{name: "line after multiline value", src: "A=\"x\ny\"\nB", line: 3},
+		{name: "unterminated variable", src: "A=${B\n", line: 1},
+		{name: "empty variable", src: "A=1\nB=${}", line: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.src))
+			var parseErr *ParseError
+			if !errors.As(err, &parseErr) {
+				t.Fatalf("Parse() error = %v, want *ParseError", err)
+			}
+			if parseErr.Line != tt.line {
+				t.Errorf("Parse() error line = %d, want %d (%v)", parseErr.Line, tt.line, err)
+			}
+		})
+	}
+}
+
+func TestLoadEnvInterpolation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+	src := "AUTOLOADERENV_TEST_HOST=file\nAUTOLOADERENV_TEST_URL=http://${AUTOLOADERENV_TEST_HOST}/\n"
+	if err := os.WriteFile(path, []byte(src), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		load     func(filenames ...string) error
+		wantHost string
+		wantURL  string
+	}{
+		{name: "existing env is kept", load: LoadEnv, wantHost: "process", wantURL: "http://process/"},
+		{name: "override", load: Overload, wantHost: "file", wantURL: "http://file/"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("AUTOLOADERENV_TEST_HOST", "process")
+			t.Setenv("AUTOLOADERENV_TEST_URL", "")
+			os.Unsetenv("AUTOLOADERENV_TEST_URL")
+
+			if err := tt.load(path); err != nil {
+				t.Fatalf("load error = %v", err)
+			}
+			if got := os.Getenv("AUTOLOADERENV_TEST_HOST"); got != tt.wantHost {
+				t.Errorf("AUTOLOADERENV_TEST_HOST = %q, want %q", got, tt.wantHost)
+			}
+			if got := os.Getenv("AUTOLOADERENV_TEST_URL"); got != tt.wantURL {
+				t.Errorf("AUTOLOADERENV_TEST_URL = %q, want %q", got, tt.wantURL)
+			}
+		})
+	}
+}
+
+func TestReadParseErrorFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(path, []byte("A=1\nB\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	_, err := Read(path)
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) {
+		t.Fatalf("Read() error = %v, want *ParseError", err)
+	}
+	if parseErr.File != path || parseErr.Line != 2 {
+		t.Errorf("Read() error = %v, want %s:2", err, path)
+	}
+}